@@ -0,0 +1,141 @@
+package helpers
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// AnswerPlaceholder 是 answers.txt 模板中待填写处的占位符
+const AnswerPlaceholder = "TODO"
+
+// AnswerQuestion 是 answers.txt 模板中的一个问题
+type AnswerQuestion struct {
+	// Label 是问题所在行的开头，用于定位问题（不区分大小写），如 "sort1 uses"
+	Label string
+	// Text 是完整的问题，可能折成多行；为空时与 Label 相同
+	Text string
+	// Name 用于报告，区分重复出现的问题；为空时使用 Label
+	Name string
+	// MinWords 是答案至少需要的词数
+	MinWords int
+}
+
+func (q AnswerQuestion) name() string {
+	if q.Name != "" {
+		return q.Name
+	}
+	return q.Label
+}
+
+func (q AnswerQuestion) text() string {
+	if q.Text != "" {
+		return q.Text
+	}
+	return q.Label
+}
+
+// Answer 是对一个问题的回答
+type Answer struct {
+	Question AnswerQuestion
+	// Found 表示文件中找到了该问题
+	Found bool
+	Text  string
+}
+
+// answerWords 把文本拆成小写的词，忽略标点
+func answerWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	})
+}
+
+// ParseAnswers 按模板中问题的顺序解析 answers.txt：
+// 每个问题的答案从问题之后开始（同一行冒号后或下一行），到下一个问题为止
+func ParseAnswers(content string, questions []AnswerQuestion) []Answer {
+	answers := make([]Answer, len(questions))
+	for i, q := range questions {
+		answers[i].Question = q
+	}
+
+	current := -1
+	// pending 是当前问题中尚未出现的词，问题折行时跳过其余部分
+	var pending []string
+	for _, line := range strings.Split(strings.ReplaceAll(content, "\r", ""), "\n") {
+		trimmed := strings.TrimSpace(line)
+		next := current + 1
+		if next < len(questions) && len(trimmed) >= len(questions[next].Label) &&
+			strings.EqualFold(trimmed[:len(questions[next].Label)], questions[next].Label) {
+			current = next
+			answers[current].Found = true
+			pending = answerWords(questions[current].text())[len(answerWords(questions[current].Label)):]
+			trimmed = strings.TrimLeft(trimmed[len(questions[current].Label):], " \t:?")
+		}
+		if current < 0 {
+			continue
+		}
+
+		if words := answerWords(trimmed); len(pending) > 0 && len(words) > 0 &&
+			len(words) <= len(pending) && slices.Equal(words, pending[:len(words)]) {
+			pending = pending[len(words):]
+			continue
+		}
+		if trimmed != "" {
+			pending = nil
+		}
+		if answers[current].Text != "" || trimmed != "" {
+			answers[current].Text += trimmed + "\n"
+		}
+	}
+
+	for i := range answers {
+		answers[i].Text = strings.TrimSpace(answers[i].Text)
+	}
+	return answers
+}
+
+// containsWords 判断 words 中是否连续出现 sub
+func containsWords(words, sub []string) bool {
+	for i := 0; i+len(sub) <= len(words); i++ {
+		if slices.Equal(words[i:i+len(sub)], sub) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckAnswers 逐个检查答案，返回每个有问题的答案的说明：
+// 缺少问题、答案为空、仍是模板占位符、照抄问题、与其他答案相同、词数不足
+func CheckAnswers(answers []Answer) []string {
+	var problems []string
+	for i, a := range answers {
+		q := a.Question
+		words := answerWords(a.Text)
+		var problem string
+		switch {
+		case !a.Found:
+			problem = fmt.Sprintf("question %q is missing; keep each question from the template and answer below it", q.text())
+		case a.Text == "":
+			problem = "answer is empty"
+		case strings.Contains(a.Text, AnswerPlaceholder):
+			problem = fmt.Sprintf("still contains the template placeholder %s", AnswerPlaceholder)
+		case len(answerWords(q.text())) >= 4 && containsWords(words, answerWords(q.text())):
+			problem = "copies the question instead of answering it"
+		default:
+			for _, other := range answers[:i] {
+				if q.MinWords > 1 && other.Found && strings.Join(words, " ") == strings.Join(answerWords(other.Text), " ") {
+					problem = fmt.Sprintf("is identical to the answer to %q; explain each one separately", other.Question.name())
+					break
+				}
+			}
+			if problem == "" && len(words) < q.MinWords {
+				problem = fmt.Sprintf("has only %d word(s); write at least %d", len(words), q.MinWords)
+			}
+		}
+		if problem != "" {
+			problems = append(problems, fmt.Sprintf("%s: %s", q.name(), problem))
+		}
+	}
+	return problems
+}
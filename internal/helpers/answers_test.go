@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var testSortQuestions = []AnswerQuestion{
+	{Label: "sort1 uses", MinWords: 1},
+	{Label: "How do you know?", Name: "sort1: How do you know?", MinWords: 5},
+	{Label: "sort2 uses", MinWords: 1},
+	{Label: "How do you know?", Name: "sort2: How do you know?", MinWords: 5},
+}
+
+func TestParseAnswers(t *testing.T) {
+	answers := ParseAnswers("sort1 uses: Bubble\n\nHow do you know?: It was slow on random input\nbut fast when sorted.\r\n\nsort2 uses:\nMerge\n",
+		testSortQuestions)
+	assert.Equal(t, "Bubble", answers[0].Text)
+	assert.Equal(t, "It was slow on random input\nbut fast when sorted.", answers[1].Text)
+	assert.Equal(t, "Merge", answers[2].Text)
+	assert.True(t, answers[2].Found)
+	assert.False(t, answers[3].Found)
+
+	// 折行的问题不算作答案
+	questions := []AnswerQuestion{{
+		Label: "If songs.db contains",
+		Text:  "If songs.db contains the top 100 songs of one listener from 2018, how would you characterize their audio aura?",
+	}}
+	answers = ParseAnswers("If songs.db contains the top 100 songs of one listener\nfrom 2018, how would you characterize their audio aura?\n\nEnergetic and danceable.\n", questions)
+	assert.Equal(t, "Energetic and danceable.", answers[0].Text)
+}
+
+func TestCheckAnswers(t *testing.T) {
+	content := `sort1 uses: TODO
+
+How do you know?: How do you know? Because.
+
+sort2 uses:
+
+How do you know?: too short
+`
+	assert.Equal(t, []string{
+		"sort1 uses: still contains the template placeholder TODO",
+		"sort1: How do you know?: copies the question instead of answering it",
+		"sort2 uses: answer is empty",
+		"sort2: How do you know?: has only 2 word(s); write at least 5",
+	}, CheckAnswers(ParseAnswers(content, testSortQuestions)))
+
+	content = `sort1 uses: Bubble
+How do you know?: It takes longest on reversed input, like bubble sort.
+sort2 uses: Merge
+How do you know?: It takes longest on reversed input, like bubble sort!
+`
+	assert.Equal(t, []string{
+		`sort2: How do you know?: is identical to the answer to "sort1: How do you know?"; explain each one separately`,
+	}, CheckAnswers(ParseAnswers(content, testSortQuestions)))
+
+	assert.Equal(t, []string{
+		`sort2 uses: question "sort2 uses" is missing; keep each question from the template and answer below it`,
+		`sort2: How do you know?: question "How do you know?" is missing; keep each question from the template and answer below it`,
+	}, CheckAnswers(ParseAnswers("sort1 uses: Bubble\nHow do you know?: It is the slowest on sorted input.", testSortQuestions)))
+}
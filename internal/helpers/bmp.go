@@ -0,0 +1,169 @@
+package helpers
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+)
+
+// bmpHeaderSize 是 BITMAPFILEHEADER (14) 与 BITMAPINFOHEADER (40) 的总长度
+const bmpHeaderSize = 54
+
+// Pixel 是一个 24 位像素
+type Pixel struct {
+	R, G, B uint8
+}
+
+func (p Pixel) String() string {
+	return fmt.Sprintf("(%d, %d, %d)", p.R, p.G, p.B)
+}
+
+// Image 是按行存储的位图，Pixels[i][j] 为第 i 行第 j 列
+// 行的顺序与文件中的存储顺序一致，和 filter 的 image[i][j] 对应
+type Image struct {
+	Width, Height int
+	Pixels        [][]Pixel
+}
+
+// NewImage 创建全黑图像
+func NewImage(width, height int) *Image {
+	img := &Image{Width: width, Height: height, Pixels: make([][]Pixel, height)}
+	for i := range img.Pixels {
+		img.Pixels[i] = make([]Pixel, width)
+	}
+	return img
+}
+
+// Clone 深拷贝图像
+func (img *Image) Clone() *Image {
+	c := NewImage(img.Width, img.Height)
+	for i := range img.Pixels {
+		copy(c.Pixels[i], img.Pixels[i])
+	}
+	return c
+}
+
+// bmpPadding 返回每行末尾的填充字节数（行长须为 4 的倍数）
+func bmpPadding(width int) int {
+	return (4 - width*3%4) % 4
+}
+
+// DecodeBMP 解析 filter 支持的 BMP：未压缩的 24 位 BMP 4.0 之前格式（54 字节头）
+func DecodeBMP(data []byte) (*Image, error) {
+	if len(data) < bmpHeaderSize {
+		return nil, fmt.Errorf("file is too short to be a BMP (%d bytes)", len(data))
+	}
+	le := binary.LittleEndian
+	if string(data[0:2]) != "BM" {
+		return nil, fmt.Errorf("not a BMP file (missing \"BM\" signature)")
+	}
+	offset := int(le.Uint32(data[10:14]))
+	infoSize := le.Uint32(data[14:18])
+	width := int(int32(le.Uint32(data[18:22])))
+	height := int(int32(le.Uint32(data[22:26])))
+	bitCount := le.Uint16(data[28:30])
+	compression := le.Uint32(data[30:34])
+
+	if offset != bmpHeaderSize || infoSize != 40 || bitCount != 24 || compression != 0 {
+		return nil, fmt.Errorf("unsupported BMP format (offset %d, header %d, %d bits, compression %d)", offset, infoSize, bitCount, compression)
+	}
+	if width <= 0 || height == 0 {
+		return nil, fmt.Errorf("invalid BMP dimensions %dx%d", width, height)
+	}
+	if height < 0 {
+		height = -height
+	}
+
+	rowSize := width*3 + bmpPadding(width)
+	if len(data) < bmpHeaderSize+rowSize*height {
+		return nil, fmt.Errorf("BMP pixel data is truncated: expected %d bytes, got %d", rowSize*height, len(data)-bmpHeaderSize)
+	}
+
+	img := NewImage(width, height)
+	for i := 0; i < height; i++ {
+		row := data[bmpHeaderSize+i*rowSize:]
+		for j := 0; j < width; j++ {
+			img.Pixels[i][j] = Pixel{B: row[j*3], G: row[j*3+1], R: row[j*3+2]}
+		}
+	}
+	return img, nil
+}
+
+// EncodeBMP 编码为自上而下存储（biHeight 为负）的 24 位 BMP，与 CS50 提供的图片格式一致
+func EncodeBMP(img *Image) []byte {
+	rowSize := img.Width*3 + bmpPadding(img.Width)
+	imageSize := rowSize * img.Height
+	data := make([]byte, bmpHeaderSize+imageSize)
+	le := binary.LittleEndian
+
+	// BITMAPFILEHEADER
+	copy(data[0:2], "BM")
+	le.PutUint32(data[2:6], uint32(len(data)))
+	le.PutUint32(data[10:14], bmpHeaderSize)
+
+	// BITMAPINFOHEADER
+	le.PutUint32(data[14:18], 40)
+	le.PutUint32(data[18:22], uint32(int32(img.Width)))
+	le.PutUint32(data[22:26], uint32(int32(-img.Height)))
+	le.PutUint16(data[26:28], 1)
+	le.PutUint16(data[28:30], 24)
+	le.PutUint32(data[34:38], uint32(imageSize))
+	le.PutUint32(data[38:42], 2835)
+	le.PutUint32(data[42:46], 2835)
+
+	for i := 0; i < img.Height; i++ {
+		row := data[bmpHeaderSize+i*rowSize:]
+		for j, p := range img.Pixels[i] {
+			row[j*3], row[j*3+1], row[j*3+2] = p.B, p.G, p.R
+		}
+	}
+	return data
+}
+
+// ReadBMP 读取 BMP 文件
+func ReadBMP(path string) (*Image, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return DecodeBMP(data)
+}
+
+// WriteBMP 写入 BMP 文件
+func WriteBMP(path string, img *Image) error {
+	return os.WriteFile(path, EncodeBMP(img), 0644)
+}
+
+// PixelDifference 描述两幅图像中第一个超出容差的像素
+type PixelDifference struct {
+	Row, Column      int
+	Expected, Actual Pixel
+}
+
+func (d PixelDifference) String() string {
+	return fmt.Sprintf("pixel at row %d, column %d: expected RGB %s, got %s", d.Row, d.Column, d.Expected, d.Actual)
+}
+
+// CompareImages 按行优先顺序比较两幅图像，每个通道允许相差 tolerance
+// 尺寸不同返回错误；全部在容差内时返回 nil
+func CompareImages(expected, actual *Image, tolerance int) (*PixelDifference, error) {
+	if expected.Width != actual.Width || expected.Height != actual.Height {
+		return nil, fmt.Errorf("image size changed: expected %dx%d, got %dx%d", expected.Width, expected.Height, actual.Width, actual.Height)
+	}
+	for i := 0; i < expected.Height; i++ {
+		for j := 0; j < expected.Width; j++ {
+			e, a := expected.Pixels[i][j], actual.Pixels[i][j]
+			if channelDiff(e.R, a.R) > tolerance || channelDiff(e.G, a.G) > tolerance || channelDiff(e.B, a.B) > tolerance {
+				return &PixelDifference{Row: i, Column: j, Expected: e, Actual: a}, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func channelDiff(a, b uint8) int {
+	if a > b {
+		return int(a - b)
+	}
+	return int(b - a)
+}
@@ -0,0 +1,154 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// diffImageTargetSize 小图像会被放大到约这个边长，方便查看
+const diffImageTargetSize = 96
+
+// diffImageGap 是三幅图之间的间隔（放大后的像素）
+const diffImageGap = 4
+
+// DiffImage 生成 "期望 | 实际 | 差异热力图" 的并排对比图
+// 热力图中：完全相同为黑色，容差内为深灰，超出容差按最大通道差从暗红到亮黄
+func DiffImage(expected, actual *Image, tolerance int) *Image {
+	w, h := expected.Width, expected.Height
+	scale := max(1, diffImageTargetSize/max(w, h))
+	gap := diffImageGap
+
+	out := NewImage(3*w*scale+2*gap, h*scale)
+	// 间隔用白色，与黑色像素区分
+	for i := range out.Pixels {
+		for j := range out.Pixels[i] {
+			out.Pixels[i][j] = Pixel{255, 255, 255}
+		}
+	}
+
+	for i := 0; i < h; i++ {
+		for j := 0; j < w; j++ {
+			e := expected.Pixels[i][j]
+			var a Pixel
+			if i < actual.Height && j < actual.Width {
+				a = actual.Pixels[i][j]
+			}
+			d := max(channelDiff(e.R, a.R), channelDiff(e.G, a.G), channelDiff(e.B, a.B))
+			for y := i * scale; y < (i+1)*scale; y++ {
+				for x := 0; x < scale; x++ {
+					out.Pixels[y][j*scale+x] = e
+					out.Pixels[y][w*scale+gap+j*scale+x] = a
+					out.Pixels[y][2*(w*scale+gap)+j*scale+x] = heatColor(d, tolerance)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// heatColor 把通道差映射为热力图颜色
+func heatColor(d, tolerance int) Pixel {
+	switch {
+	case d == 0:
+		return Pixel{0, 0, 0}
+	case d <= tolerance:
+		return Pixel{64, 64, 64}
+	}
+	// d 在 (tolerance, 255] 之间：红色分量饱和后再加绿色，得到暗红 → 红 → 黄
+	v := 96 + d*2*(255-96)/255
+	if v <= 255 {
+		return Pixel{uint8(v), 0, 0}
+	}
+	return Pixel{255, uint8(min(255, v-255)), 0}
+}
+
+// SummarizeImageDifferences 用一句话描述出错像素的分布，帮助定位常见的边界错误
+// 图像完全一致（在容差内）时返回空字符串
+func SummarizeImageDifferences(expected, actual *Image, tolerance int) string {
+	w, h := expected.Width, expected.Height
+	if actual.Width != w || actual.Height != h {
+		return ""
+	}
+
+	type cell struct{ i, j int }
+	var wrong []cell
+	var channels [3]bool
+	maxDiff := 0
+	for i := 0; i < h; i++ {
+		for j := 0; j < w; j++ {
+			e, a := expected.Pixels[i][j], actual.Pixels[i][j]
+			diffs := [3]int{channelDiff(e.R, a.R), channelDiff(e.G, a.G), channelDiff(e.B, a.B)}
+			if max(diffs[0], diffs[1], diffs[2]) <= tolerance {
+				continue
+			}
+			wrong = append(wrong, cell{i, j})
+			for c, d := range diffs {
+				if d > tolerance {
+					channels[c] = true
+				}
+				maxDiff = max(maxDiff, d)
+			}
+		}
+	}
+	if len(wrong) == 0 {
+		return ""
+	}
+
+	all := func(pred func(i, j int) bool) bool {
+		for _, c := range wrong {
+			if !pred(c.i, c.j) {
+				return false
+			}
+		}
+		return true
+	}
+	border := func(i, j int) bool { return i == 0 || j == 0 || i == h-1 || j == w-1 }
+	borderCount := w*h - max(0, w-2)*max(0, h-2)
+
+	var where string
+	switch {
+	case len(wrong) == w*h:
+		where = "every pixel is wrong"
+	case w > 1 && all(func(i, j int) bool { return j == w-1 }):
+		where = "only the last column is wrong (check the loop bounds for the rightmost pixels)"
+	case w > 1 && all(func(i, j int) bool { return j == 0 }):
+		where = "only the first column is wrong"
+	case h > 1 && all(func(i, j int) bool { return i == h-1 }):
+		where = "only the last row is wrong (check the loop bounds for the bottom row)"
+	case h > 1 && all(func(i, j int) bool { return i == 0 }):
+		where = "only the first row is wrong"
+	case w > 2 && h > 2 && all(func(i, j int) bool { return (i == 0 || i == h-1) && (j == 0 || j == w-1) }):
+		where = "only the corner pixels are wrong (check how neighbours outside the image are handled)"
+	case w > 2 && h > 2 && len(wrong) == borderCount && all(border):
+		where = "all edge pixels are wrong while interior pixels are correct (check how neighbours outside the image are handled)"
+	case w > 2 && h > 2 && all(border):
+		where = "only pixels on the image border are wrong (check how neighbours outside the image are handled)"
+	case w > 2 && h > 2 && all(func(i, j int) bool { return !border(i, j) }):
+		where = "only interior pixels are wrong; the border is correct"
+	case w > 1 && all(func(i, j int) bool { return j >= w/2 }):
+		where = "only the right half of the image is wrong"
+	case w > 1 && all(func(i, j int) bool { return j < (w+1)/2 }):
+		where = "only the left half of the image is wrong"
+	default:
+		where = fmt.Sprintf("%d of %d pixels are wrong", len(wrong), w*h)
+	}
+
+	var notes []string
+	names := [3]string{"red", "green", "blue"}
+	var only []string
+	for c, bad := range channels {
+		if bad {
+			only = append(only, names[c])
+		}
+	}
+	if len(only) == 1 {
+		notes = append(notes, "only the "+only[0]+" channel differs")
+	}
+	if maxDiff <= tolerance+1 {
+		notes = append(notes, "values are off by at most 1 (check rounding)")
+	}
+	if len(notes) > 0 {
+		where += "; " + strings.Join(notes, ", ")
+	}
+	return where
+}
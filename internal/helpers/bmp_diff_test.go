@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarizeImageDifferences(t *testing.T) {
+	expected := NewImage(5, 4)
+	for i := range expected.Pixels {
+		for j := range expected.Pixels[i] {
+			expected.Pixels[i][j] = Pixel{100, 100, 100}
+		}
+	}
+	wrongWhere := func(pred func(i, j int) bool, d uint8) *Image {
+		actual := expected.Clone()
+		for i := range actual.Pixels {
+			for j := range actual.Pixels[i] {
+				if pred(i, j) {
+					actual.Pixels[i][j].R += d
+				}
+			}
+		}
+		return actual
+	}
+
+	assert.Equal(t, "", SummarizeImageDifferences(expected, expected, 0))
+
+	lastColumn := wrongWhere(func(i, j int) bool { return j == 4 }, 10)
+	assert.Contains(t, SummarizeImageDifferences(expected, lastColumn, 0), "only the last column is wrong")
+
+	edges := wrongWhere(func(i, j int) bool { return i == 0 || j == 0 || i == 3 || j == 4 }, 10)
+	assert.Contains(t, SummarizeImageDifferences(expected, edges, 0), "all edge pixels are wrong")
+
+	interior := wrongWhere(func(i, j int) bool { return i == 1 && j == 2 }, 10)
+	assert.Contains(t, SummarizeImageDifferences(expected, interior, 0), "only interior pixels are wrong")
+
+	rounding := wrongWhere(func(i, j int) bool { return (i+j)%2 == 0 }, 1)
+	summary := SummarizeImageDifferences(expected, rounding, 0)
+	assert.Contains(t, summary, "10 of 20 pixels are wrong")
+	assert.Contains(t, summary, "only the red channel differs")
+	assert.Contains(t, summary, "check rounding")
+	assert.Equal(t, "", SummarizeImageDifferences(expected, rounding, 1))
+}
+
+func TestDiffImage(t *testing.T) {
+	expected := NewImage(2, 1)
+	expected.Pixels[0][0] = Pixel{10, 20, 30}
+	actual := expected.Clone()
+	actual.Pixels[0][1] = Pixel{200, 0, 0}
+
+	diff := DiffImage(expected, actual, 0)
+	scale := diffImageTargetSize / 2
+	assert.Equal(t, 3*2*scale+2*diffImageGap, diff.Width)
+	assert.Equal(t, scale, diff.Height)
+
+	heat := 2 * (2*scale + diffImageGap)
+	assert.Equal(t, Pixel{10, 20, 30}, diff.Pixels[0][0])
+	assert.Equal(t, Pixel{200, 0, 0}, diff.Pixels[0][2*scale+diffImageGap+scale])
+	assert.Equal(t, Pixel{0, 0, 0}, diff.Pixels[0][heat])
+	assert.NotEqual(t, Pixel{0, 0, 0}, diff.Pixels[0][heat+scale])
+}
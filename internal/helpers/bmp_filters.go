@@ -0,0 +1,112 @@
+package helpers
+
+import "math"
+
+// 以下是 filter 各函数的参考实现，均返回新图像、不修改输入
+// 舍入使用 math.Round（与 C 的 round 一致：.5 远离零）
+
+// clampChannel 把结果限制在 [0, 255]
+func clampChannel(v float64) uint8 {
+	return uint8(math.Min(255, math.Max(0, math.Round(v))))
+}
+
+// Grayscale 把每个像素的三个通道设为其平均值
+func Grayscale(img *Image) *Image {
+	out := img.Clone()
+	for i := range out.Pixels {
+		for j, p := range out.Pixels[i] {
+			avg := clampChannel((float64(p.R) + float64(p.G) + float64(p.B)) / 3.0)
+			out.Pixels[i][j] = Pixel{avg, avg, avg}
+		}
+	}
+	return out
+}
+
+// Sepia 按 CS50 给出的系数转换为棕褐色，超过 255 时截断
+func Sepia(img *Image) *Image {
+	out := img.Clone()
+	for i := range out.Pixels {
+		for j, p := range out.Pixels[i] {
+			r, g, b := float64(p.R), float64(p.G), float64(p.B)
+			out.Pixels[i][j] = Pixel{
+				R: clampChannel(.393*r + .769*g + .189*b),
+				G: clampChannel(.349*r + .686*g + .168*b),
+				B: clampChannel(.272*r + .534*g + .131*b),
+			}
+		}
+	}
+	return out
+}
+
+// Reflect 水平翻转
+func Reflect(img *Image) *Image {
+	out := img.Clone()
+	for i := range out.Pixels {
+		row := out.Pixels[i]
+		for j := 0; j < len(row)/2; j++ {
+			row[j], row[len(row)-1-j] = row[len(row)-1-j], row[j]
+		}
+	}
+	return out
+}
+
+// Blur 3x3 盒式模糊，边缘和角落只对图像内的像素取平均
+func Blur(img *Image) *Image {
+	out := img.Clone()
+	for i := 0; i < img.Height; i++ {
+		for j := 0; j < img.Width; j++ {
+			var r, g, b, n float64
+			for di := -1; di <= 1; di++ {
+				for dj := -1; dj <= 1; dj++ {
+					y, x := i+di, j+dj
+					if y < 0 || y >= img.Height || x < 0 || x >= img.Width {
+						continue
+					}
+					p := img.Pixels[y][x]
+					r += float64(p.R)
+					g += float64(p.G)
+					b += float64(p.B)
+					n++
+				}
+			}
+			out.Pixels[i][j] = Pixel{clampChannel(r / n), clampChannel(g / n), clampChannel(b / n)}
+		}
+	}
+	return out
+}
+
+// sobelX / sobelY 是 Sobel 算子的卷积核
+var (
+	sobelX = [3][3]float64{{-1, 0, 1}, {-2, 0, 2}, {-1, 0, 1}}
+	sobelY = [3][3]float64{{-1, -2, -1}, {0, 0, 0}, {1, 2, 1}}
+)
+
+// Edges 用 Sobel 算子检测边缘，图像外的像素视为黑色，结果为 sqrt(Gx²+Gy²) 并截断到 255
+func Edges(img *Image) *Image {
+	out := img.Clone()
+	for i := 0; i < img.Height; i++ {
+		for j := 0; j < img.Width; j++ {
+			var gx, gy [3]float64
+			for di := -1; di <= 1; di++ {
+				for dj := -1; dj <= 1; dj++ {
+					y, x := i+di, j+dj
+					if y < 0 || y >= img.Height || x < 0 || x >= img.Width {
+						continue
+					}
+					p := img.Pixels[y][x]
+					kx, ky := sobelX[di+1][dj+1], sobelY[di+1][dj+1]
+					for c, v := range [3]float64{float64(p.R), float64(p.G), float64(p.B)} {
+						gx[c] += kx * v
+						gy[c] += ky * v
+					}
+				}
+			}
+			var ch [3]uint8
+			for c := range ch {
+				ch[c] = clampChannel(math.Sqrt(gx[c]*gx[c] + gy[c]*gy[c]))
+			}
+			out.Pixels[i][j] = Pixel{ch[0], ch[1], ch[2]}
+		}
+	}
+	return out
+}
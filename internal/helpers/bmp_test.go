@@ -0,0 +1,112 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// imageFromRows 用 "r g b" 组成的行构造图像
+func imageFromRows(t *testing.T, width int, pixels ...string) *Image {
+	t.Helper()
+	require.Zero(t, len(pixels)%width)
+	img := NewImage(width, len(pixels)/width)
+	for k, s := range pixels {
+		var p Pixel
+		_, err := fmt.Sscanf(s, "%d %d %d", &p.R, &p.G, &p.B)
+		require.NoError(t, err)
+		img.Pixels[k/width][k%width] = p
+	}
+	return img
+}
+
+// formatImage 以 testing.c 的格式逐行输出像素
+func formatImage(img *Image) string {
+	var b strings.Builder
+	for _, row := range img.Pixels {
+		for _, p := range row {
+			fmt.Fprintf(&b, "%d %d %d\n", p.R, p.G, p.B)
+		}
+	}
+	return b.String()
+}
+
+// filterSample 是 check50 中 3x3 的 grayscale / sepia / reflect / blur 测试图像
+func filterSample(t *testing.T) *Image {
+	return imageFromRows(t, 3,
+		"10 20 30", "40 50 60", "70 80 90",
+		"110 130 140", "120 140 150", "130 150 160",
+		"200 210 220", "220 230 240", "240 250 255")
+}
+
+func TestReferenceFilters(t *testing.T) {
+	img := filterSample(t)
+
+	assert.Equal(t, "20 20 20\n50 50 50\n80 80 80\n"+
+		"127 127 127\n137 137 137\n147 147 147\n"+
+		"210 210 210\n230 230 230\n248 248 248\n", formatImage(Grayscale(img)))
+	assert.Equal(t, "25 22 17\n66 58 45\n106 94 74\n"+
+		"170 151 118\n183 163 127\n197 175 136\n"+
+		"255 251 195\n255 255 214\n255 255 232\n", formatImage(Sepia(img)))
+	assert.Equal(t, "70 80 90\n40 50 60\n10 20 30\n"+
+		"130 150 160\n120 140 150\n110 130 140\n"+
+		"240 250 255\n220 230 240\n200 210 220\n", formatImage(Reflect(img)))
+	assert.Equal(t, "70 85 95\n80 95 105\n90 105 115\n"+
+		"117 130 140\n127 140 149\n137 150 159\n"+
+		"163 178 188\n170 185 194\n178 193 201\n", formatImage(Blur(img)))
+
+	// 输入不应被修改
+	assert.Equal(t, filterSample(t), img)
+}
+
+func TestEdges(t *testing.T) {
+	img := imageFromRows(t, 3,
+		"0 10 25", "0 10 30", "40 60 80",
+		"20 30 90", "30 40 100", "80 70 90",
+		"20 20 40", "30 10 30", "50 40 10")
+	assert.Equal(t, "76 117 255\n213 228 255\n192 190 255\n"+
+		"114 102 255\n210 150 60\n103 108 255\n"+
+		"114 117 255\n200 197 255\n210 190 255\n", formatImage(Edges(img)))
+}
+
+func TestBMPRoundTrip(t *testing.T) {
+	// 宽度 1、3、5 分别需要 1、3、1 字节的行填充
+	for _, width := range []int{1, 3, 4, 5} {
+		img := NewImage(width, 3)
+		for i := range img.Pixels {
+			for j := range img.Pixels[i] {
+				img.Pixels[i][j] = Pixel{uint8(i * 40), uint8(j * 30), uint8(i + j)}
+			}
+		}
+		data := EncodeBMP(img)
+		assert.Equal(t, 54+(width*3+bmpPadding(width))*3, len(data))
+
+		decoded, err := DecodeBMP(data)
+		require.NoError(t, err)
+		assert.Equal(t, img, decoded)
+	}
+
+	_, err := DecodeBMP([]byte("not a bitmap at all, definitely not one; padding padding"))
+	assert.ErrorContains(t, err, "BM")
+}
+
+func TestCompareImages(t *testing.T) {
+	expected := filterSample(t)
+	actual := expected.Clone()
+	actual.Pixels[1][2].G += 2
+
+	diff, err := CompareImages(expected, actual, 1)
+	require.NoError(t, err)
+	require.NotNil(t, diff)
+	assert.Equal(t, "pixel at row 1, column 2: expected RGB (130, 150, 160), got (130, 152, 160)", diff.String())
+
+	diff, err = CompareImages(expected, actual, 2)
+	require.NoError(t, err)
+	assert.Nil(t, diff)
+
+	_, err = CompareImages(expected, NewImage(2, 3), 0)
+	assert.ErrorContains(t, err, "expected 3x3, got 2x3")
+}
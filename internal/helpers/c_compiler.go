@@ -10,7 +10,8 @@ import (
 // source: 源文件名 (如 "hello.c")
 // output: 输出文件名 (如 "hello")
 // needBootllm: 是否需要 bootllm.h (使用 -I.. 引入父目录)
-func CompileC(workDir, source, output string, needBootllm bool) error {
+// extraFlags: 额外的编译参数 (如 SanitizerFlags)
+func CompileC(workDir, source, output string, needBootllm bool, extraFlags ...string) error {
 	args := []string{
 		"-o", output,
 		source,
@@ -23,13 +24,14 @@ func CompileC(workDir, source, output string, needBootllm bool) error {
 	if needBootllm {
 		args = append(args, "-I..")
 	}
+	args = append(args, extraFlags...)
 
 	cmd := exec.Command("clang", args...)
 	cmd.Dir = workDir
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("compilation failed: %s\nOutput:\n%s", err, string(out))
+		return fmt.Errorf("compilation failed: %s\n%s", err, ExplainClangOutput(workDir, string(out)))
 	}
 
 	return nil
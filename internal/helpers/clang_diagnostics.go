@@ -0,0 +1,266 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Diagnostic 表示 clang 输出中的一条诊断信息
+// 例如: hello.c:5:5: error: expected ';' after expression
+type Diagnostic struct {
+	File     string
+	Line     int
+	Column   int
+	Severity string // error / warning / note / fatal error
+	Message  string
+	Flag     string // 如 -Wunused-variable，可能为空
+}
+
+// diagnosticLineRegex 匹配 "file:line:col: severity: message [flag]"
+var diagnosticLineRegex = regexp.MustCompile(`^(.+?):(\d+):(\d+): (fatal error|error|warning|note): (.*?)(?: \[([^\]]+)\])?$`)
+
+// ParseClangDiagnostics 将 clang 输出解析为结构化诊断
+// 源码片段行（"  5 | ..."、"    | ^"）以及汇总行会被忽略
+func ParseClangDiagnostics(output string) []Diagnostic {
+	var diags []Diagnostic
+	for _, line := range strings.Split(output, "\n") {
+		m := diagnosticLineRegex.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if m == nil {
+			continue
+		}
+		lineNo, _ := strconv.Atoi(m[2])
+		col, _ := strconv.Atoi(m[3])
+		diags = append(diags, Diagnostic{
+			File:     m[1],
+			Line:     lineNo,
+			Column:   col,
+			Severity: m[4],
+			Message:  m[5],
+			Flag:     diagnosticFlag(m[6]),
+		})
+	}
+	return diags
+}
+
+// diagnosticFlag 从 "-Werror,-Wunused-variable" 中取出真正的警告名
+func diagnosticFlag(raw string) string {
+	parts := strings.Split(raw, ",")
+	return parts[len(parts)-1]
+}
+
+// diagnosticRule 描述一种常见错误及对应的提示
+type diagnosticRule struct {
+	pattern *regexp.Regexp
+	hint    func(m []string) string
+}
+
+// headerForFunction 常用库函数所在的头文件
+var headerForFunction = map[string]string{
+	"printf":     "stdio.h",
+	"fopen":      "stdio.h",
+	"fclose":     "stdio.h",
+	"fread":      "stdio.h",
+	"fwrite":     "stdio.h",
+	"strlen":     "string.h",
+	"strcmp":     "string.h",
+	"strcpy":     "string.h",
+	"strcasecmp": "strings.h",
+	"toupper":    "ctype.h",
+	"tolower":    "ctype.h",
+	"isalpha":    "ctype.h",
+	"isdigit":    "ctype.h",
+	"isupper":    "ctype.h",
+	"islower":    "ctype.h",
+	"isspace":    "ctype.h",
+	"ispunct":    "ctype.h",
+	"malloc":     "stdlib.h",
+	"free":       "stdlib.h",
+	"atoi":       "stdlib.h",
+	"round":      "math.h",
+	"pow":        "math.h",
+	"sqrt":       "math.h",
+}
+
+// diagnosticRules 常见新手错误规则表，按顺序匹配，命中第一条即停止
+var diagnosticRules = []diagnosticRule{
+	{
+		regexp.MustCompile(`'bootllm\.h' file not found`),
+		func(m []string) string {
+			return "The compiler can't find bootllm.h. Make sure you spelled it #include <bootllm.h> and that bootllm.h exists in the parent directory."
+		},
+	},
+	{
+		regexp.MustCompile(`(?:call to undeclared function|implicit declaration of function) '(get_\w+)'`),
+		func(m []string) string {
+			return fmt.Sprintf("%s is declared in bootllm.h. Did you forget to add #include <bootllm.h> at the top of your file?", m[1])
+		},
+	},
+	{
+		regexp.MustCompile(`(?:call to undeclared (?:library )?function|implicit declaration of (?:library )?function) '(\w+)'`),
+		func(m []string) string {
+			if header, ok := headerForFunction[m[1]]; ok {
+				return fmt.Sprintf("%s is declared in %s. Did you forget to add #include <%s> at the top of your file?", m[1], header, header)
+			}
+			return fmt.Sprintf("The compiler doesn't know about %s yet. If you wrote %s yourself, declare its prototype above main (or check its spelling).", m[1], m[1])
+		},
+	},
+	{
+		regexp.MustCompile(`(?:unknown type name|use of undeclared identifier) 'string'`),
+		func(m []string) string {
+			return "The string type is defined in bootllm.h. Did you forget to add #include <bootllm.h> at the top of your file?"
+		},
+	},
+	{
+		regexp.MustCompile(`expected ';'`),
+		func(m []string) string {
+			return "You're missing a semicolon. Statements in C end with ; — look at the end of this line (or the line just above it)."
+		},
+	},
+	{
+		regexp.MustCompile(`unused variable '(\w+)'`),
+		func(m []string) string {
+			return fmt.Sprintf("You declared %s but never used it. Because this course compiles with -Werror, warnings are errors: either use %s or delete its declaration.", m[1], m[1])
+		},
+	},
+	{
+		regexp.MustCompile(`variable '(\w+)' is uninitialized when used here`),
+		func(m []string) string {
+			return fmt.Sprintf("%s is used before it's given a value. Initialize it when you declare it, e.g. int %s = 0;", m[1], m[1])
+		},
+	},
+	{
+		regexp.MustCompile(`use of undeclared identifier '(\w+)'`),
+		func(m []string) string {
+			return fmt.Sprintf("The compiler doesn't recognize %s. Check its spelling, and make sure it's declared before this line and inside the current scope (variables declared inside { } don't exist outside them).", m[1])
+		},
+	},
+	{
+		regexp.MustCompile(`format specifies type '([^']+)' but the argument has type '([^']+)'`),
+		func(m []string) string {
+			return fmt.Sprintf("The format code in your printf expects %s, but you passed %s. Use %%i for int, %%f for float, %%s for string, %%c for char.", m[1], m[2])
+		},
+	},
+	{
+		regexp.MustCompile(`expected '\}'`),
+		func(m []string) string {
+			return "Your curly braces don't match up. Every { needs a matching } — check the blocks above this line."
+		},
+	},
+	{
+		regexp.MustCompile(`non-void function does not return a value|control reaches end of non-void function`),
+		func(m []string) string {
+			return "This function promises to return a value but can reach its end without a return statement. Add a return on every path."
+		},
+	},
+	{
+		regexp.MustCompile(`(?:relational|equality) comparison result unused|using the result of an assignment as a condition`),
+		func(m []string) string {
+			return "Did you mix up = and ==? Use == to compare values and = to assign them."
+		},
+	},
+}
+
+// hintFor 返回诊断对应的提示，没有匹配的规则时返回空字符串
+func hintFor(d Diagnostic) string {
+	for _, rule := range diagnosticRules {
+		if m := rule.pattern.FindStringSubmatch(d.Message); m != nil {
+			return rule.hint(m)
+		}
+	}
+	return ""
+}
+
+// ExplainClangOutput 将 clang 输出转换为面向初学者的说明
+// 每条 error/warning 给出位置、原始信息、源码片段以及（若有）针对性提示
+// 无法解析出诊断时返回原始输出
+func ExplainClangOutput(workDir, output string) string {
+	diags := ParseClangDiagnostics(output)
+
+	var b strings.Builder
+	for _, d := range diags {
+		if d.Severity == "note" {
+			continue
+		}
+		fmt.Fprintf(&b, "%s:%d:%d: %s: %s\n", d.File, d.Line, d.Column, d.Severity, d.Message)
+		if snippet := sourceSnippet(workDir, d); snippet != "" {
+			b.WriteString(snippet)
+		}
+		if hint := hintFor(d); hint != "" {
+			fmt.Fprintf(&b, "  Hint: %s\n", hint)
+		}
+		b.WriteString("\n")
+	}
+
+	if b.Len() == 0 {
+		return output
+	}
+
+	// 链接错误、"In file included from" 等无法解析的行原样附在后面
+	if rest := unparsedClangLines(output); len(rest) > 0 {
+		b.WriteString(strings.Join(rest, "\n"))
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// clangSnippetRegex 匹配源码片段行，如 "    5 |     int x;" 和 "      |         ^"
+var clangSnippetRegex = regexp.MustCompile(`^\s*\d*\s*\|`)
+
+// clangSummaryRegex 匹配 "3 errors generated." 与 "1 warning and 2 errors generated."
+var clangSummaryRegex = regexp.MustCompile(`^\d+ (?:errors?|warnings?)(?: and \d+ (?:errors?|warnings?))? generated\.$`)
+
+// unparsedClangLines 返回既不是诊断、也不是源码片段或汇总的非空行
+func unparsedClangLines(output string) []string {
+	var rest []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.TrimSpace(line) == "" || diagnosticLineRegex.MatchString(line) ||
+			clangSnippetRegex.MatchString(line) || clangSummaryRegex.MatchString(line) {
+			continue
+		}
+		rest = append(rest, line)
+	}
+	return rest
+}
+
+// sourceSnippet 读取出错行并在对应列下方标出 ^
+func sourceSnippet(workDir string, d Diagnostic) string {
+	text, ok := readSourceLine(workDir, d.File, d.Line)
+	if !ok {
+		return ""
+	}
+
+	// 保留制表符，使 ^ 与源码对齐
+	var pad strings.Builder
+	for i := 0; i < d.Column-1 && i < len(text); i++ {
+		if text[i] == '\t' {
+			pad.WriteByte('\t')
+		} else {
+			pad.WriteByte(' ')
+		}
+	}
+
+	gutter := strconv.Itoa(d.Line)
+	return fmt.Sprintf("  %s | %s\n  %s | %s^\n", gutter, text, strings.Repeat(" ", len(gutter)), pad.String())
+}
+
+// readSourceLine 读取源文件的第 line 行（从 1 开始），相对路径基于 workDir
+func readSourceLine(workDir, file string, line int) (string, bool) {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+
+	lines := strings.Split(string(content), "\n")
+	if line < 1 || line > len(lines) {
+		return "", false
+	}
+	return strings.TrimRight(lines[line-1], "\r"), true
+}
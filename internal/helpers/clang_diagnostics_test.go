@@ -0,0 +1,89 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleClangOutput = `hello.c:5:19: error: call to undeclared function 'get_string'; ISO C99 and later do not support implicit function declarations [-Wimplicit-function-declaration]
+    5 |     string name = get_string("What's your name? ");
+      |                   ^
+hello.c:6:9: error: unused variable 'x' [-Werror,-Wunused-variable]
+    6 |     int x;
+      |         ^
+hello.c:7:39: error: expected ';' after expression
+    7 |     printf("hello, %s\n", name)
+      |                                ^
+      |                                ;
+3 errors generated.
+`
+
+func TestParseClangDiagnostics(t *testing.T) {
+	diags := ParseClangDiagnostics(sampleClangOutput)
+	require.Len(t, diags, 3)
+
+	assert.Equal(t, Diagnostic{
+		File:     "hello.c",
+		Line:     5,
+		Column:   19,
+		Severity: "error",
+		Message:  "call to undeclared function 'get_string'; ISO C99 and later do not support implicit function declarations",
+		Flag:     "-Wimplicit-function-declaration",
+	}, diags[0])
+	assert.Equal(t, "-Wunused-variable", diags[1].Flag)
+	assert.Equal(t, "expected ';' after expression", diags[2].Message)
+	assert.Equal(t, "", diags[2].Flag)
+}
+
+func TestHintFor(t *testing.T) {
+	tests := []struct {
+		message  string
+		contains string
+	}{
+		{"call to undeclared function 'get_int'; ISO C99 and later do not support implicit function declarations", "#include <bootllm.h>"},
+		{"implicit declaration of function 'get_int' is invalid in C99", "#include <bootllm.h>"},
+		{"call to undeclared library function 'strlen' with type 'unsigned long (const char *)'", "#include <string.h>"},
+		{"'bootllm.h' file not found", "parent directory"},
+		{"unknown type name 'string'", "bootllm.h"},
+		{"expected ';' after expression", "semicolon"},
+		{"unused variable 'x'", "-Werror"},
+		{"use of undeclared identifier 'n'", "scope"},
+	}
+
+	for _, tc := range tests {
+		hint := hintFor(Diagnostic{Message: tc.message})
+		assert.Contains(t, hint, tc.contains, "message=%q", tc.message)
+	}
+
+	assert.Equal(t, "", hintFor(Diagnostic{Message: "something nobody has seen before"}))
+}
+
+func TestExplainClangOutput(t *testing.T) {
+	dir := t.TempDir()
+	source := "#include <stdio.h>\n\nint main(void)\n{\n    string name = get_string(\"What's your name? \");\n    int x;\n    printf(\"hello, %s\\n\", name)\n}\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "hello.c"), []byte(source), 0644))
+
+	explained := ExplainClangOutput(dir, sampleClangOutput)
+	assert.Contains(t, explained, "  5 |     string name = get_string(\"What's your name? \");\n    |                   ^\n")
+	assert.Contains(t, explained, "Hint: get_string is declared in bootllm.h")
+	assert.NotContains(t, explained, "3 errors generated")
+
+	// 诊断之外无法解析的行（头文件包含链、链接错误）原样附在后面
+	withLinker := "In file included from hello.c:1:\n" + sampleClangOutput +
+		"/usr/bin/ld: /tmp/hello-1a2b.o: in function `main':\nhello.c:(.text+0x1a): undefined reference to `get_string'\n" +
+		"clang: error: linker command failed with exit code 1 (use -v to see invocation)\n"
+	explained = ExplainClangOutput(dir, withLinker)
+	assert.Contains(t, explained, "Hint: get_string is declared in bootllm.h")
+	assert.True(t, strings.HasSuffix(explained, "\n\nIn file included from hello.c:1:\n"+
+		"/usr/bin/ld: /tmp/hello-1a2b.o: in function `main':\nhello.c:(.text+0x1a): undefined reference to `get_string'\n"+
+		"clang: error: linker command failed with exit code 1 (use -v to see invocation)"), explained)
+	assert.NotContains(t, explained, "3 errors generated")
+
+	// 无法解析时原样返回
+	assert.Equal(t, "ld: something broke", ExplainClangOutput(dir, "ld: something broke"))
+}
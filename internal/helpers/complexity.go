@@ -0,0 +1,213 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// ComplexityBudget 是一个 stage 的规模测试总时长上限
+	ComplexityBudget = 90 * time.Second
+
+	// ComplexityRepetitions 是每个规模重复运行的次数，取最短耗时以降低噪声
+	ComplexityRepetitions = 3
+
+	// MinMeasurableSpread 最大与最小规模的耗时差低于此值时，认为程序太快而无法判断复杂度
+	MinMeasurableSpread = 150 * time.Millisecond
+)
+
+// ComplexityEnabled 判断是否开启复杂度检查（BOOTLLM_COMPLEXITY=1）
+// 计时结果受机器负载影响，默认关闭
+func ComplexityEnabled() bool {
+	return os.Getenv("BOOTLLM_COMPLEXITY") == "1"
+}
+
+// ComplexityTimeout 在开启复杂度检查时为 stage 超时追加 ComplexityBudget
+func ComplexityTimeout(base time.Duration) time.Duration {
+	if ComplexityEnabled() {
+		return base + ComplexityBudget
+	}
+	return base
+}
+
+// ComplexityClass 表示拟合出的增长阶
+type ComplexityClass int
+
+const (
+	Linear ComplexityClass = iota
+	Linearithmic
+	Quadratic
+)
+
+func (c ComplexityClass) String() string {
+	switch c {
+	case Linear:
+		return "O(n)"
+	case Linearithmic:
+		return "O(n log n)"
+	case Quadratic:
+		return "O(n²)"
+	}
+	return "unknown"
+}
+
+// complexityModels 候选模型及其增长函数
+var complexityModels = []struct {
+	class ComplexityClass
+	f     func(n float64) float64
+}{
+	{Linear, func(n float64) float64 { return n }},
+	{Linearithmic, func(n float64) float64 { return n * math.Log2(n) }},
+	{Quadratic, func(n float64) float64 { return n * n }},
+}
+
+// Measurement 表示某个输入规模下的耗时
+type Measurement struct {
+	N        int
+	Duration time.Duration
+}
+
+// ErrBudgetExceeded 表示一次运行用完了剩余的测量时间而被终止
+var ErrBudgetExceeded = errors.New("time budget exhausted")
+
+// MeasureScaling 对每个规模运行 run 若干次，记录最短耗时
+// 测量总时长以 ComplexityBudget 的一半为限：每次运行拿到的 budget 是剩余时间，
+// 超时的运行应返回已用时间与 ErrBudgetExceeded，其已用时间作为耗时下限记录；
+// 时间用完后跳过剩余的重复和更大的规模，此时若测得的规模不足 3 个则无法拟合，返回错误
+func MeasureScaling(sizes []int, run func(n int, budget time.Duration) (time.Duration, error)) ([]Measurement, error) {
+	var ms []Measurement
+	deadline := time.Now().Add(ComplexityBudget / 2)
+	for _, n := range sizes {
+		best := time.Duration(math.MaxInt64)
+		exhausted := false
+		for i := 0; i < ComplexityRepetitions; i++ {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				exhausted = true
+				break
+			}
+			d, err := run(n, remaining)
+			if errors.Is(err, ErrBudgetExceeded) {
+				best = min(best, d)
+				exhausted = true
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("n=%d: %v", n, err)
+			}
+			best = min(best, d)
+		}
+		if best != time.Duration(math.MaxInt64) {
+			ms = append(ms, Measurement{N: n, Duration: best})
+		}
+		if exhausted {
+			if len(ms) < 3 {
+				return ms, fmt.Errorf("ran out of the %v measuring budget at n=%d: %w", ComplexityBudget/2, n, ErrBudgetExceeded)
+			}
+			break
+		}
+	}
+	return ms, nil
+}
+
+// ComplexityFit 是复杂度拟合结果
+type ComplexityFit struct {
+	Class ComplexityClass
+	// Measurable 为 false 表示耗时差太小，Class 不可信
+	Measurable   bool
+	Measurements []Measurement
+}
+
+// FitComplexity 用最小二乘法把 t = a + b·f(n) 分别拟合到各候选模型（b 须 > 0），
+// 常数项 a 吸收进程启动等固定开销；为抵抗计时噪声，
+// 增长更快的模型只有在残差不到当前最佳一半时才会被选中
+func FitComplexity(ms []Measurement) ComplexityFit {
+	fit := ComplexityFit{Class: Linear, Measurements: ms}
+	if len(ms) < 3 {
+		return fit
+	}
+
+	lo, hi := ms[0].Duration, ms[0].Duration
+	for _, m := range ms {
+		lo = min(lo, m.Duration)
+		hi = max(hi, m.Duration)
+	}
+	if hi-lo < MinMeasurableSpread {
+		return fit
+	}
+	fit.Measurable = true
+
+	bestSSE := math.Inf(1)
+	for _, model := range complexityModels {
+		xs := make([]float64, len(ms))
+		ys := make([]float64, len(ms))
+		for i, m := range ms {
+			xs[i] = model.f(float64(m.N))
+			ys[i] = m.Duration.Seconds()
+		}
+		a, b := linearRegression(xs, ys)
+		if b <= 0 {
+			continue
+		}
+		sse := 0.0
+		for i := range xs {
+			r := ys[i] - (a + b*xs[i])
+			sse += r * r
+		}
+		if sse < bestSSE*0.5 || math.IsInf(bestSSE, 1) {
+			bestSSE = sse
+			fit.Class = model.class
+		}
+	}
+
+	return fit
+}
+
+// linearRegression 返回 y = a + b·x 的最小二乘解
+func linearRegression(xs, ys []float64) (a, b float64) {
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	den := n*sxx - sx*sx
+	if den == 0 {
+		return sy / n, 0
+	}
+	b = (n*sxy - sx*sy) / den
+	a = (sy - b*sx) / n
+	return a, b
+}
+
+// String 以 "n=1000: 12ms, n=2000: 25ms → O(n)" 的形式描述拟合结果
+func (f ComplexityFit) String() string {
+	parts := make([]string, len(f.Measurements))
+	for i, m := range f.Measurements {
+		parts[i] = fmt.Sprintf("n=%d: %v", m.N, m.Duration.Round(time.Millisecond))
+	}
+	if !f.Measurable {
+		return strings.Join(parts, ", ") + " → too fast to measure"
+	}
+	return strings.Join(parts, ", ") + " → " + f.Class.String()
+}
+
+// CheckScaling 测量并拟合复杂度，期望低于 O(n²) 却拟合出 O(n²) 时返回错误
+// O(n) 与 O(n log n) 在计时上难以区分，因此两者互不判错
+func CheckScaling(sizes []int, expected ComplexityClass, run func(n int, budget time.Duration) (time.Duration, error)) (ComplexityFit, error) {
+	ms, err := MeasureScaling(sizes, run)
+	if err != nil {
+		return ComplexityFit{}, err
+	}
+	fit := FitComplexity(ms)
+	if fit.Measurable && expected < Quadratic && fit.Class == Quadratic {
+		return fit, fmt.Errorf("running time grows like %s, expected about %s (%s)", fit.Class, expected, fit)
+	}
+	return fit, nil
+}
@@ -0,0 +1,79 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// synthetic 按 t = startup + f(n) 生成测量数据
+func synthetic(startup time.Duration, f func(n float64) float64) []Measurement {
+	var ms []Measurement
+	for _, n := range []int{25000, 50000, 100000, 200000} {
+		ms = append(ms, Measurement{N: n, Duration: startup + time.Duration(f(float64(n)))})
+	}
+	return ms
+}
+
+func TestFitComplexity(t *testing.T) {
+	// 线性：每个元素 10µs，外加 50ms 启动开销
+	linear := synthetic(50*time.Millisecond, func(n float64) float64 { return n * 10e3 })
+	fit := FitComplexity(linear)
+	assert.True(t, fit.Measurable)
+	assert.Equal(t, Linear, fit.Class)
+
+	// 平方：每对元素 0.05ns
+	quadratic := synthetic(50*time.Millisecond, func(n float64) float64 { return n * n * 0.05 })
+	fit = FitComplexity(quadratic)
+	assert.True(t, fit.Measurable)
+	assert.Equal(t, Quadratic, fit.Class)
+
+	// 耗时差太小时不做判断
+	fast := synthetic(5*time.Millisecond, func(n float64) float64 { return n * 10 })
+	assert.False(t, FitComplexity(fast).Measurable)
+}
+
+func TestFitComplexityToleratesNoise(t *testing.T) {
+	ms := synthetic(50*time.Millisecond, func(n float64) float64 { return n * 10e3 })
+	// ±5% 的噪声不应让线性程序被判为平方
+	ms[1].Duration = ms[1].Duration * 95 / 100
+	ms[3].Duration = ms[3].Duration * 105 / 100
+	assert.NotEqual(t, Quadratic, FitComplexity(ms).Class)
+}
+
+// budgetedRun 模拟耗时为 f(n) 的程序：超出剩余时间时像 timeCommand 一样被终止
+func budgetedRun(calls map[int]int, f func(n int) time.Duration) func(n int, budget time.Duration) (time.Duration, error) {
+	return func(n int, budget time.Duration) (time.Duration, error) {
+		calls[n]++
+		if d := f(n); d <= budget {
+			return d, nil
+		}
+		return budget, ErrBudgetExceeded
+	}
+}
+
+func TestMeasureScalingBudget(t *testing.T) {
+	sizes := []int{1000, 2000, 4000, 8000, 16000}
+	quadratic := func(n int) time.Duration { return time.Duration(n) * time.Duration(n) * time.Microsecond }
+
+	// 第 4 个规模用完了时间：该规模只运行一次，以剩余时间为下限记录，更大的规模被跳过，仍可拟合
+	calls := map[int]int{}
+	ms, err := MeasureScaling(sizes, budgetedRun(calls, quadratic))
+	require.NoError(t, err)
+	require.Len(t, ms, 4)
+	assert.Equal(t, map[int]int{1000: 3, 2000: 3, 4000: 3, 8000: 1}, calls)
+	assert.LessOrEqual(t, ms[3].Duration, ComplexityBudget/2)
+
+	// 测得的规模不足 3 个时返回错误，而不是拿不完整的数据拟合
+	calls = map[int]int{}
+	_, err = MeasureScaling(sizes, budgetedRun(calls, func(n int) time.Duration { return 16 * quadratic(n) }))
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.ErrorContains(t, err, "n=2000")
+	assert.Equal(t, map[int]int{1000: 3, 2000: 1}, calls)
+
+	fit, err := CheckScaling([]int{1000, 2000, 3000, 4000}, Linear, budgetedRun(map[int]int{}, quadratic))
+	assert.ErrorContains(t, err, "grows like O(n²)")
+	assert.Equal(t, Quadratic, fit.Class)
+}
@@ -0,0 +1,207 @@
+package helpers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/bootllm/tester-utils/runner"
+)
+
+// DebuggerTimeout 是用调试器重新运行程序获取 backtrace 的最长时间
+const DebuggerTimeout = 30 * time.Second
+
+// crashSignals 视为“程序崩溃”的信号及说明
+// SIGKILL / SIGTERM 通常来自超时或内存限制，不在此列
+var crashSignals = map[syscall.Signal]struct {
+	name        string
+	description string
+}{
+	syscall.SIGSEGV: {"SIGSEGV", "segmentation fault: your program touched memory it doesn't own (a NULL pointer, an array index out of bounds, or memory that was already freed)"},
+	syscall.SIGABRT: {"SIGABRT", "abort: usually a failed assert, or malloc/free detecting a corrupted heap (double free, writing past a malloc'd block)"},
+	syscall.SIGFPE:  {"SIGFPE", "arithmetic exception: usually an integer division or modulo by zero"},
+	syscall.SIGBUS:  {"SIGBUS", "bus error: a misaligned or invalid memory access"},
+	syscall.SIGILL:  {"SIGILL", "illegal instruction: often a function that fell off its end without returning a value"},
+}
+
+// StackFrame 表示 backtrace 中的一帧
+type StackFrame struct {
+	Index    int
+	Function string
+	File     string // 无调试信息时为空
+	Line     int
+}
+
+// CrashError 表示程序被信号终止
+type CrashError struct {
+	Program     string
+	Signal      string
+	Description string
+	// Frames 只包含学生代码中的帧（按从内到外排列）
+	Frames []StackFrame
+	// Sources 与 Frames 一一对应的源码行
+	Sources []string
+	Err     error
+}
+
+func (e *CrashError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s was terminated by %s (%s)", e.Program, e.Signal, e.Description)
+
+	if len(e.Frames) > 0 {
+		b.WriteString("\nBacktrace (your code):")
+		for i, f := range e.Frames {
+			fmt.Fprintf(&b, "\n  #%d %s at %s:%d", f.Index, f.Function, filepath.Base(f.File), f.Line)
+			if e.Sources[i] != "" {
+				fmt.Fprintf(&b, "\n      %d | %s", f.Line, strings.TrimSpace(e.Sources[i]))
+			}
+		}
+	}
+	return b.String()
+}
+
+func (e *CrashError) Unwrap() error {
+	return e.Err
+}
+
+// crashSignal 根据退出码判断程序是否因崩溃信号终止
+// tester-utils 将被信号终止的进程退出码记为 128 + signal
+func crashSignal(exitCode int) (syscall.Signal, bool) {
+	if exitCode <= 128 {
+		return 0, false
+	}
+	sig := syscall.Signal(exitCode - 128)
+	_, ok := crashSignals[sig]
+	return sig, ok
+}
+
+// ExplainCrash 检查 runner 的运行结果，若程序因信号崩溃则返回 *CrashError
+// program / args 用于在调试器中重放同一次调用，否则原样返回 err
+func ExplainCrash(r *runner.Runner, err error, workDir, program string, args ...string) error {
+	if err == nil || r.Result() == nil {
+		return err
+	}
+	sig, ok := crashSignal(r.Result().ExitCode)
+	if !ok {
+		return err
+	}
+	return newCrashError(sig, workDir, program, args, err)
+}
+
+// ExplainCmdCrash 与 ExplainCrash 相同，但用于 exec.Cmd 的运行结果
+func ExplainCmdCrash(cmd *exec.Cmd, err error) error {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	if !ok || !status.Signaled() {
+		return err
+	}
+	if _, ok := crashSignals[status.Signal()]; !ok {
+		return err
+	}
+	return newCrashError(status.Signal(), cmd.Dir, cmd.Args[0], cmd.Args[1:], err)
+}
+
+func newCrashError(sig syscall.Signal, workDir, program string, args []string, err error) *CrashError {
+	info := crashSignals[sig]
+	crash := &CrashError{
+		Program:     filepath.Base(program),
+		Signal:      info.name,
+		Description: info.description,
+		Err:         err,
+	}
+
+	frames := CaptureBacktrace(workDir, program, args...)
+	for _, f := range frames {
+		if f.File == "" || !fileInDir(workDir, f.File) {
+			continue
+		}
+		source, _ := readSourceLine(workDir, f.File, f.Line)
+		crash.Frames = append(crash.Frames, f)
+		crash.Sources = append(crash.Sources, source)
+	}
+
+	return crash
+}
+
+// fileInDir 判断 backtrace 中的源文件是否属于学生目录
+func fileInDir(workDir, file string) bool {
+	path := file
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	rel, err := filepath.Rel(workDir, path)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return false
+	}
+	_, err = os.Stat(path)
+	return err == nil
+}
+
+// CaptureBacktrace 在 gdb（优先）或 lldb 中以批处理模式重新运行程序并解析 backtrace
+// 两者都不可用或程序没有崩溃时返回 nil
+func CaptureBacktrace(workDir, program string, args ...string) []StackFrame {
+	if !strings.Contains(program, "/") {
+		program = "./" + program
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), DebuggerTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if _, err := exec.LookPath("gdb"); err == nil {
+		gdbArgs := []string{"-batch", "-nx", "-ex", "run", "-ex", "bt 20", "--args", program}
+		cmd = exec.CommandContext(ctx, "gdb", append(gdbArgs, args...)...)
+	} else if _, err := exec.LookPath("lldb"); err == nil {
+		lldbArgs := []string{"--batch", "-o", "run", "-k", "bt 20", "--", program}
+		cmd = exec.CommandContext(ctx, "lldb", append(lldbArgs, args...)...)
+	} else {
+		return nil
+	}
+	cmd.Dir = workDir
+
+	out, _ := cmd.CombinedOutput()
+	return ParseBacktrace(string(out))
+}
+
+var (
+	// #0  0x0000555555555189 in check (word=0x7ffd...) at dictionary.c:42
+	// #1  main (argc=3, argv=0x7ffd...) at speller.c:111
+	gdbFrameRegex = regexp.MustCompile(`^#(\d+)\s+(?:0x[0-9a-f]+ in )?(\S+) \(.*\)(?: at (.+):(\d+))?`)
+	//   * frame #0: 0x0000000100003f50 speller`check(word="cat") at dictionary.c:42:9
+	lldbFrameRegex = regexp.MustCompile("frame #(\\d+): 0x[0-9a-f]+ [^`]*`([\\w$.]+)(?:\\(.*?\\))?(?: at ([^:]+):(\\d+)(?::\\d+)?)?")
+)
+
+// ParseBacktrace 解析 gdb 或 lldb 的 backtrace 输出
+func ParseBacktrace(output string) []StackFrame {
+	var frames []StackFrame
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		m := gdbFrameRegex.FindStringSubmatch(line)
+		if m == nil {
+			m = lldbFrameRegex.FindStringSubmatch(line)
+		}
+		if m == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(m[1])
+		lineNo, _ := strconv.Atoi(m[4])
+		frames = append(frames, StackFrame{
+			Index:    index,
+			Function: m[2],
+			File:     m[3],
+			Line:     lineNo,
+		})
+	}
+	return frames
+}
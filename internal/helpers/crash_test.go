@@ -0,0 +1,68 @@
+package helpers
+
+import (
+	"errors"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBacktraceGdb(t *testing.T) {
+	output := `Program received signal SIGSEGV, Segmentation fault.
+0x0000555555555189 in check (word=0x7fffffffd9a0 "cat") at dictionary.c:42
+42	    while (cursor->next != NULL)
+#0  0x0000555555555189 in check (word=0x7fffffffd9a0 "cat") at dictionary.c:42
+#1  0x00005555555558a2 in main (argc=3, argv=0x7fffffffdbf8) at speller.c:111
+#2  0x00007ffff7dd8d90 in __libc_start_call_main () from /lib/x86_64-linux-gnu/libc.so.6
+`
+	frames := ParseBacktrace(output)
+	require.Len(t, frames, 3)
+	assert.Equal(t, StackFrame{Index: 0, Function: "check", File: "dictionary.c", Line: 42}, frames[0])
+	assert.Equal(t, StackFrame{Index: 1, Function: "main", File: "speller.c", Line: 111}, frames[1])
+	assert.Equal(t, "", frames[2].File)
+}
+
+func TestParseBacktraceLldb(t *testing.T) {
+	output := "* thread #1, name = 'tideman_test', stop reason = signal SIGSEGV: invalid address (fault address: 0x0)\n" +
+		"  * frame #0: 0x0000555555555189 tideman_test`lock_pairs at tideman_combined_test.c:180:13\n" +
+		"    frame #1: 0x00005555555558a2 tideman_test`main(argc=3, argv=0x00007fffffffdbf8) at tideman_combined_test.c:320:9\n" +
+		"    frame #2: 0x00007ffff7dd8d90 libc.so.6`__libc_start_call_main + 128\n"
+
+	frames := ParseBacktrace(output)
+	require.Len(t, frames, 3)
+	assert.Equal(t, StackFrame{Index: 0, Function: "lock_pairs", File: "tideman_combined_test.c", Line: 180}, frames[0])
+	assert.Equal(t, "main", frames[1].Function)
+	assert.Equal(t, "__libc_start_call_main", frames[2].Function)
+	assert.Equal(t, "", frames[2].File)
+}
+
+func TestCrashSignal(t *testing.T) {
+	_, ok := crashSignal(139)
+	assert.True(t, ok, "128 + SIGSEGV")
+	_, ok = crashSignal(134)
+	assert.True(t, ok, "128 + SIGABRT")
+	_, ok = crashSignal(137)
+	assert.False(t, ok, "SIGKILL comes from timeouts, not crashes")
+	_, ok = crashSignal(1)
+	assert.False(t, ok)
+}
+
+func TestExplainCmdCrash(t *testing.T) {
+	cmd := exec.Command("sh", "-c", "kill -SEGV $$")
+	cmd.Dir = t.TempDir()
+	err := cmd.Run()
+	require.Error(t, err)
+
+	explained := ExplainCmdCrash(cmd, err)
+	var crash *CrashError
+	require.True(t, errors.As(explained, &crash))
+	assert.Equal(t, "SIGSEGV", crash.Signal)
+	assert.Contains(t, crash.Error(), "sh was terminated by SIGSEGV")
+
+	// 普通的非零退出不是崩溃
+	cmd = exec.Command("sh", "-c", "exit 1")
+	err = cmd.Run()
+	assert.Equal(t, err, ExplainCmdCrash(cmd, err))
+}
@@ -0,0 +1,189 @@
+package helpers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bootllm/tester-utils/random"
+)
+
+// DNANoMatch 是 dna.py 找不到匹配时的输出
+const DNANoMatch = "No match"
+
+// LongestSTRRun 返回 str 在 sequence 中最长的连续重复次数
+// 与 CS50 提供的 longest_match 相同：从每个位置出发向后数连续重复
+func LongestSTRRun(sequence, str string) int {
+	longest := 0
+	for i := 0; i < len(sequence); i++ {
+		run := 0
+		for j := i; strings.HasPrefix(sequence[j:], str); j += len(str) {
+			run++
+		}
+		longest = max(longest, run)
+	}
+	return longest
+}
+
+// DNAProfile 是数据库中一个人的各 STR 计数
+type DNAProfile struct {
+	Name   string
+	Counts []int
+}
+
+// DNADatabase 是 dna.py 读取的 CSV 数据库
+type DNADatabase struct {
+	STRs   []string
+	People []DNAProfile
+}
+
+// CSV 以 "name,STR1,STR2,..." 的格式输出数据库
+func (db *DNADatabase) CSV() string {
+	var b strings.Builder
+	b.WriteString("name," + strings.Join(db.STRs, ",") + "\n")
+	for _, p := range db.People {
+		b.WriteString(p.Name)
+		for _, c := range p.Counts {
+			fmt.Fprintf(&b, ",%d", c)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Profile 计算序列在数据库各 STR 上的最长连续重复次数
+func (db *DNADatabase) Profile(sequence string) []int {
+	counts := make([]int, len(db.STRs))
+	for i, str := range db.STRs {
+		counts[i] = LongestSTRRun(sequence, str)
+	}
+	return counts
+}
+
+// Identify 返回所有 STR 计数都与序列一致的人，没有时返回 DNANoMatch
+func (db *DNADatabase) Identify(sequence string) string {
+	profile := db.Profile(sequence)
+	for _, p := range db.People {
+		if equalInts(p.Counts, profile) {
+			return p.Name
+		}
+	}
+	return DNANoMatch
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// dnaNames 是生成数据库时使用的名字
+var dnaNames = []string{
+	"Alice", "Bob", "Charlie", "Dana", "Eve", "Frank", "Grace", "Heidi", "Ivan", "Judy",
+	"Mallory", "Niaj", "Olivia", "Peggy", "Rupert", "Sybil", "Trent", "Victor", "Walter", "Zoe",
+}
+
+// GenerateDNADatabase 生成包含 strCount 个不同 STR、peopleCount 个不同档案的数据库
+func GenerateDNADatabase(strCount, peopleCount int) *DNADatabase {
+	db := &DNADatabase{}
+	seen := map[string]bool{}
+	for len(db.STRs) < strCount {
+		var b strings.Builder
+		for i := random.RandomInt(3, 7); i > 0; i-- {
+			b.WriteString(dnaBases[random.RandomInt(0, len(dnaBases))])
+		}
+		str := b.String()
+		// 避免形如 "AAAA" 的单一碱基 STR，它会与自身的移位重叠
+		if seen[str] || strings.Count(str, str[:1]) == len(str) {
+			continue
+		}
+		seen[str] = true
+		db.STRs = append(db.STRs, str)
+	}
+
+	profiles := map[string]bool{}
+	for _, name := range random.ShuffleArray(append([]string(nil), dnaNames...))[:peopleCount] {
+		for {
+			counts := make([]int, strCount)
+			for i := range counts {
+				counts[i] = random.RandomInt(1, 16)
+			}
+			key := fmt.Sprint(counts)
+			if profiles[key] {
+				continue
+			}
+			profiles[key] = true
+			db.People = append(db.People, DNAProfile{Name: name, Counts: counts})
+			break
+		}
+	}
+	return db
+}
+
+// dnaBases 是 DNA 序列的字母表
+var dnaBases = []string{"A", "C", "G", "T"}
+
+// GenerateDNASequence 生成长度约为 length 的序列，使各 STR 的最长连续重复次数恰为 counts：
+// 除了最长的一段，还会插入更短的以及等长的（并列最长）重复段
+// 随机填充偶尔会意外延长某段重复，因此会重试直到 Profile 与 counts 一致；
+// 多次失败后返回最后一次的结果，ok 为 false
+func GenerateDNASequence(db *DNADatabase, counts []int, length int) (sequence string, ok bool) {
+	for attempt := 0; attempt < 50; attempt++ {
+		var segments []string
+		for i, str := range db.STRs {
+			if counts[i] == 0 {
+				continue
+			}
+			segments = append(segments, strings.Repeat(str, counts[i]))
+			if random.RandomInt(0, 3) == 0 {
+				segments = append(segments, strings.Repeat(str, counts[i]))
+			}
+			if counts[i] > 1 {
+				segments = append(segments, strings.Repeat(str, random.RandomInt(1, counts[i])))
+			}
+		}
+		segments = random.ShuffleArray(segments)
+
+		filler := max(1, (length-len(strings.Join(segments, "")))/(len(segments)+1))
+		var b strings.Builder
+		for _, s := range segments {
+			b.WriteString(randomBases(random.RandomInt(1, filler+1)))
+			b.WriteString(s)
+		}
+		b.WriteString(randomBases(random.RandomInt(1, filler+1)))
+
+		sequence = b.String()
+		if equalInts(db.Profile(sequence), counts) {
+			return sequence, true
+		}
+	}
+	return sequence, false
+}
+
+// RandomDNASequence 生成长度约为 n 的随机 DNA 序列，其中穿插 strs 的连续重复（最多 maxRun 次）
+func RandomDNASequence(n int, strs []string, maxRun int) string {
+	var b strings.Builder
+	for b.Len() < n {
+		if random.RandomInt(0, 20) == 0 {
+			str := strs[random.RandomInt(0, len(strs))]
+			b.WriteString(strings.Repeat(str, random.RandomInt(1, maxRun+1)))
+			continue
+		}
+		b.WriteString(randomBases(1))
+	}
+	return b.String()
+}
+
+// randomBases 生成 n 个随机碱基
+func randomBases(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = dnaBases[random.RandomInt(0, len(dnaBases))][0]
+	}
+	return string(b)
+}
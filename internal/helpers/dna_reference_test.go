@@ -0,0 +1,59 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bootllm/tester-utils/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLongestSTRRun(t *testing.T) {
+	assert.Equal(t, 0, LongestSTRRun("GGGG", "AGATC"))
+	assert.Equal(t, 1, LongestSTRRun("TTAGATCTT", "AGATC"))
+	assert.Equal(t, 3, LongestSTRRun("AGATCAGATCTTAGATCAGATCAGATCAG", "AGATC"))
+	// 重叠的起点不会多算
+	assert.Equal(t, 3, LongestSTRRun("ATATATA", "AT"))
+}
+
+func TestDNADatabase(t *testing.T) {
+	db := &DNADatabase{
+		STRs: []string{"AGATC", "AATG"},
+		People: []DNAProfile{
+			{Name: "Alice", Counts: []int{2, 1}},
+			{Name: "Bob", Counts: []int{1, 3}},
+		},
+	}
+	assert.Equal(t, "name,AGATC,AATG\nAlice,2,1\nBob,1,3\n", db.CSV())
+	assert.Equal(t, "Bob", db.Identify("CCAGATCGGAATGAATGAATGCC"))
+	assert.Equal(t, DNANoMatch, db.Identify("CCAGATCGGAATGAATGCC"))
+}
+
+func TestGenerateDNASequence(t *testing.T) {
+	random.Init()
+	for i := 0; i < 20; i++ {
+		db := GenerateDNADatabase(random.RandomInt(3, 9), random.RandomInt(2, 10))
+		require.NotEmpty(t, db.People)
+		assert.Equal(t, len(db.People)+1, strings.Count(db.CSV(), "\n"))
+
+		target := db.People[random.RandomInt(0, len(db.People))]
+		seq, ok := GenerateDNASequence(db, target.Counts, 2000)
+		if !ok {
+			continue
+		}
+		assert.Equal(t, target.Name, db.Identify(seq))
+	}
+}
+
+func TestRandomDNASequence(t *testing.T) {
+	random.Init()
+	strs := []string{"AGATC", "AATG"}
+	seq := RandomDNASequence(5000, strs, 8)
+	assert.GreaterOrEqual(t, len(seq), 5000)
+	assert.Empty(t, strings.Trim(seq, "ACGT"))
+
+	for _, str := range strs {
+		assert.Contains(t, seq, str)
+	}
+}
@@ -0,0 +1,382 @@
+package helpers
+
+import (
+	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MaxCandidates 是 plurality / runoff / tideman 允许的最多候选人数
+const MaxCandidates = 9
+
+// ElectionSeed 返回随机选举使用的种子：BOOTLLM_ELECTION_SEED 指定时使用它以复现失败，否则随机选取
+func ElectionSeed() int64 {
+	return SeedFromEnv("BOOTLLM_ELECTION_SEED")
+}
+
+// electionNames 是生成选举时使用的候选人名字
+var electionNames = []string{"Alice", "Bob", "Charlie", "David", "Erin", "Frank", "Grace", "Heidi", "Ivan"}
+
+// Election 是一次选举的候选人与选票
+type Election struct {
+	Candidates []string
+	// Ballots 中每张选票按偏好顺序列出候选人；plurality 的选票只有一个名字（可能无效）
+	Ballots [][]string
+}
+
+// Inputs 返回程序依次读取的输入：选民人数，然后是每张选票上的名字
+func (e Election) Inputs() []string {
+	inputs := []string{strconv.Itoa(len(e.Ballots))}
+	for _, ballot := range e.Ballots {
+		inputs = append(inputs, ballot...)
+	}
+	return inputs
+}
+
+// candidateIndex 返回候选人编号，不存在时返回 -1
+func (e Election) candidateIndex(name string) int {
+	for i, c := range e.Candidates {
+		if c == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// PluralityWinners 返回得票最多的候选人（并列时全部返回，按候选人顺序），无效选票不计
+func PluralityWinners(e Election) []string {
+	votes := make([]int, len(e.Candidates))
+	for _, ballot := range e.Ballots {
+		if i := e.candidateIndex(ballot[0]); i >= 0 {
+			votes[i]++
+		}
+	}
+	best := 0
+	for _, v := range votes {
+		best = max(best, v)
+	}
+	var winners []string
+	for i, v := range votes {
+		if v == best {
+			winners = append(winners, e.Candidates[i])
+		}
+	}
+	return winners
+}
+
+// RunoffWinners 按 runoff 的即时决选规则计票：
+// 有人获得超过半数的第一偏好时胜出；否则若剩余候选人票数全部相同则全部并列胜出，
+// 不然淘汰所有票数最少的候选人，进入下一轮
+func RunoffWinners(e Election) []string {
+	n := len(e.Candidates)
+	eliminated := make([]bool, n)
+	for {
+		votes := make([]int, n)
+		for _, ballot := range e.Ballots {
+			for _, name := range ballot {
+				if i := e.candidateIndex(name); !eliminated[i] {
+					votes[i]++
+					break
+				}
+			}
+		}
+
+		for i, v := range votes {
+			if !eliminated[i] && v > len(e.Ballots)/2 {
+				return []string{e.Candidates[i]}
+			}
+		}
+
+		minVotes := len(e.Ballots) + 1
+		for i, v := range votes {
+			if !eliminated[i] {
+				minVotes = min(minVotes, v)
+			}
+		}
+		tie := true
+		for i, v := range votes {
+			if !eliminated[i] && v != minVotes {
+				tie = false
+			}
+		}
+		if tie {
+			var winners []string
+			for i := range votes {
+				if !eliminated[i] {
+					winners = append(winners, e.Candidates[i])
+				}
+			}
+			return winners
+		}
+		for i, v := range votes {
+			if !eliminated[i] && v == minVotes {
+				eliminated[i] = true
+			}
+		}
+	}
+}
+
+// rankedPair 是 tideman 中的一对候选人及胜者的得票
+type rankedPair struct {
+	winner, loser, strength int
+}
+
+// maxPairOrderings 是 RankedPairsWinners 愿意枚举的同强度数对排列总数上限
+const maxPairOrderings = 5040
+
+// RankedPairsWinners 按 tideman 的 Ranked Pairs 规则计算胜者：
+// 数对按胜者得票数降序排序，依次锁定不会形成环的数对，胜者是锁定图中唯一的源点
+// 强度相同的数对可以以任意顺序排列，因此会枚举所有排列，返回所有可能的胜者
+// 排列过多，或某个排列下源点不唯一时 ok 为 false，调用方应换一组选票
+func RankedPairsWinners(e Election) (winners []string, ok bool) {
+	n := len(e.Candidates)
+	prefs := make([][]int, n)
+	for i := range prefs {
+		prefs[i] = make([]int, n)
+	}
+	for _, ballot := range e.Ballots {
+		for a := 0; a < len(ballot); a++ {
+			for b := a + 1; b < len(ballot); b++ {
+				prefs[e.candidateIndex(ballot[a])][e.candidateIndex(ballot[b])]++
+			}
+		}
+	}
+
+	var pairs []rankedPair
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			if prefs[i][j] > prefs[j][i] {
+				pairs = append(pairs, rankedPair{i, j, prefs[i][j]})
+			}
+		}
+	}
+	sort.SliceStable(pairs, func(a, b int) bool { return pairs[a].strength > pairs[b].strength })
+
+	// 按强度分组，统计排列总数
+	var groups [][]rankedPair
+	orderings := 1
+	for start := 0; start < len(pairs); {
+		end := start
+		for end < len(pairs) && pairs[end].strength == pairs[start].strength {
+			end++
+		}
+		groups = append(groups, pairs[start:end])
+		for k := 2; k <= end-start; k++ {
+			orderings *= k
+			if orderings > maxPairOrderings {
+				return nil, false
+			}
+		}
+		start = end
+	}
+
+	found := map[int]bool{}
+	ok = true
+	var visit func(g int, order []rankedPair)
+	visit = func(g int, order []rankedPair) {
+		if !ok {
+			return
+		}
+		if g == len(groups) {
+			source, unique := lockedSource(n, order)
+			if !unique {
+				ok = false
+				return
+			}
+			found[source] = true
+			return
+		}
+		permute(groups[g], func(p []rankedPair) {
+			visit(g+1, append(append([]rankedPair(nil), order...), p...))
+		})
+	}
+	visit(0, nil)
+	if !ok {
+		return nil, false
+	}
+
+	for i, c := range e.Candidates {
+		if found[i] {
+			winners = append(winners, c)
+		}
+	}
+	return winners, true
+}
+
+// lockedSource 依次锁定数对（跳过会形成环的），返回锁定图的源点及其是否唯一
+func lockedSource(n int, pairs []rankedPair) (int, bool) {
+	locked := make([][]bool, n)
+	for i := range locked {
+		locked[i] = make([]bool, n)
+	}
+	var reaches func(from, to int) bool
+	reaches = func(from, to int) bool {
+		if from == to {
+			return true
+		}
+		for k := 0; k < n; k++ {
+			if locked[from][k] && reaches(k, to) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, p := range pairs {
+		// 若败者已能到达胜者，加入这条边会形成环
+		if !reaches(p.loser, p.winner) {
+			locked[p.winner][p.loser] = true
+		}
+	}
+
+	source, count := -1, 0
+	for j := 0; j < n; j++ {
+		hasIncoming := false
+		for i := 0; i < n; i++ {
+			if locked[i][j] {
+				hasIncoming = true
+			}
+		}
+		if !hasIncoming {
+			source = j
+			count++
+		}
+	}
+	return source, count == 1
+}
+
+// permute 对 items 的每个排列调用 fn
+func permute(items []rankedPair, fn func([]rankedPair)) {
+	p := append([]rankedPair(nil), items...)
+	var rec func(k int)
+	rec = func(k int) {
+		if k == len(p) {
+			fn(p)
+			return
+		}
+		for i := k; i < len(p); i++ {
+			p[k], p[i] = p[i], p[k]
+			rec(k + 1)
+			p[k], p[i] = p[i], p[k]
+		}
+	}
+	rec(0)
+}
+
+// randomCandidates 随机选出 k 个候选人（保持 electionNames 中的顺序）
+func randomCandidates(rng *rand.Rand, k int) []string {
+	idx := rng.Perm(len(electionNames))[:k]
+	sort.Ints(idx)
+	names := make([]string, k)
+	for i, j := range idx {
+		names[i] = electionNames[j]
+	}
+	return names
+}
+
+// randomRanking 返回候选人的一个随机排列
+func randomRanking(rng *rand.Rand, candidates []string) []string {
+	ranking := make([]string, len(candidates))
+	for i, j := range rng.Perm(len(candidates)) {
+		ranking[i] = candidates[j]
+	}
+	return ranking
+}
+
+// GeneratePluralityElection 生成 plurality 选举：随机投票，或刻意构造的多人并列，并混入无效选票
+func GeneratePluralityElection(rng *rand.Rand) Election {
+	e := Election{Candidates: randomCandidates(rng, rng.Intn(MaxCandidates-1)+2)}
+	if rng.Intn(2) == 0 {
+		// 前 t 个候选人各得 votes 票，其余得票更少
+		t := rng.Intn(len(e.Candidates)) + 1
+		votes := rng.Intn(4) + 2
+		for i, c := range e.Candidates {
+			count := votes
+			if i >= t {
+				count = rng.Intn(votes)
+			}
+			for ; count > 0; count-- {
+				e.Ballots = append(e.Ballots, []string{c})
+			}
+		}
+	} else {
+		for v := rng.Intn(15) + 1; v > 0; v-- {
+			e.Ballots = append(e.Ballots, []string{e.Candidates[rng.Intn(len(e.Candidates))]})
+		}
+	}
+	for k := rng.Intn(3); k > 0; k-- {
+		e.Ballots = append(e.Ballots, []string{"Mallory"})
+	}
+	rng.Shuffle(len(e.Ballots), func(i, j int) { e.Ballots[i], e.Ballots[j] = e.Ballots[j], e.Ballots[i] })
+	return e
+}
+
+// GenerateRunoffElection 生成 runoff 选举：随机排名，或轮换排名使所有候选人并列
+func GenerateRunoffElection(rng *rand.Rand) Election {
+	e := Election{Candidates: randomCandidates(rng, rng.Intn(MaxCandidates-1)+2)}
+	if rng.Intn(4) == 0 {
+		// 每个候选人都恰好在 m 张选票上排第一
+		m := rng.Intn(3) + 1
+		k := len(e.Candidates)
+		for r := 0; r < k*m; r++ {
+			ballot := make([]string, k)
+			for i := range ballot {
+				ballot[i] = e.Candidates[(r+i)%k]
+			}
+			e.Ballots = append(e.Ballots, ballot)
+		}
+	} else {
+		for v := rng.Intn(20) + 1; v > 0; v-- {
+			e.Ballots = append(e.Ballots, randomRanking(rng, e.Candidates))
+		}
+	}
+	rng.Shuffle(len(e.Ballots), func(i, j int) { e.Ballots[i], e.Ballots[j] = e.Ballots[j], e.Ballots[i] })
+	return e
+}
+
+// GenerateTidemanElection 生成胜者确定的 tideman 选举，一半的情况下包含
+// 由三名候选人的循环偏好（A>B>C、B>C>A、C>A>B）构成的孔多塞悖论
+func GenerateTidemanElection(rng *rand.Rand) Election {
+	for {
+		e := Election{Candidates: randomCandidates(rng, rng.Intn(MaxCandidates-2)+3)}
+		if rng.Intn(2) == 0 {
+			cycle := randomRanking(rng, e.Candidates)[:3]
+			for r := 0; r < 3; r++ {
+				for copies := rng.Intn(3) + 1; copies > 0; copies-- {
+					rest := randomRanking(rng, e.Candidates)
+					ballot := []string{cycle[r], cycle[(r+1)%3], cycle[(r+2)%3]}
+					for _, c := range rest {
+						if c != cycle[0] && c != cycle[1] && c != cycle[2] {
+							ballot = append(ballot, c)
+						}
+					}
+					e.Ballots = append(e.Ballots, ballot)
+				}
+			}
+		}
+		for v := rng.Intn(8) + 1; v > 0; v-- {
+			e.Ballots = append(e.Ballots, randomRanking(rng, e.Candidates))
+		}
+		rng.Shuffle(len(e.Ballots), func(i, j int) { e.Ballots[i], e.Ballots[j] = e.Ballots[j], e.Ballots[i] })
+		if _, ok := RankedPairsWinners(e); ok {
+			return e
+		}
+	}
+}
+
+// electionPrompt 匹配选举程序的输入提示；pty 不回显输入，相邻的提示会连在一起
+var electionPrompt = regexp.MustCompile(`(Number of voters|Vote|Rank \d+): ?`)
+
+// ParseElectionOutput 去掉输入提示和 "Invalid vote." 后，返回程序输出的每一行（通常是胜者名单）
+func ParseElectionOutput(output string) []string {
+	output = electionPrompt.ReplaceAllString(strings.ReplaceAll(output, "\r", ""), "")
+	var lines []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && line != "Invalid vote." {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
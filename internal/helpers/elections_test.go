@@ -0,0 +1,98 @@
+package helpers
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func ballots(rankings ...string) [][]string {
+	var b [][]string
+	for _, r := range rankings {
+		var ballot []string
+		for _, c := range r {
+			ballot = append(ballot, map[rune]string{'A': "Alice", 'B': "Bob", 'C': "Charlie", 'D': "David"}[c])
+		}
+		b = append(b, ballot)
+	}
+	return b
+}
+
+func TestPluralityWinners(t *testing.T) {
+	e := Election{
+		Candidates: []string{"Alice", "Bob", "Charlie"},
+		Ballots:    [][]string{{"Bob"}, {"Mallory"}, {"Alice"}, {"Bob"}, {"Alice"}, {"Charlie"}},
+	}
+	assert.Equal(t, []string{"Alice", "Bob"}, PluralityWinners(e))
+
+	e.Ballots = append(e.Ballots, []string{"Charlie"}, []string{"Charlie"})
+	assert.Equal(t, []string{"Charlie"}, PluralityWinners(e))
+	assert.Equal(t, []string{"8", "Bob", "Mallory", "Alice", "Bob", "Alice", "Charlie", "Charlie", "Charlie"}, e.Inputs())
+}
+
+func TestRunoffWinners(t *testing.T) {
+	candidates := []string{"Alice", "Bob", "Charlie"}
+	// 第一轮无人过半，Charlie 被淘汰后其选票转给 Bob
+	e := Election{Candidates: candidates, Ballots: ballots("ABC", "ABC", "BCA", "BAC", "CBA")}
+	assert.Equal(t, []string{"Bob"}, RunoffWinners(e))
+
+	// 所有候选人并列
+	e = Election{Candidates: candidates, Ballots: ballots("ABC", "BCA", "CAB")}
+	assert.Equal(t, candidates, RunoffWinners(e))
+
+	// 同时淘汰两名票数最少的候选人
+	e = Election{Candidates: []string{"Alice", "Bob", "Charlie", "David"}, Ballots: ballots("ABCD", "ABCD", "BADC", "CADB", "DBAC")}
+	assert.Equal(t, []string{"Alice"}, RunoffWinners(e))
+}
+
+func TestRankedPairsWinners(t *testing.T) {
+	candidates := []string{"Alice", "Bob", "Charlie"}
+	// CS50 示例：A>B (7), C>A (6), B>C (5)；锁定 B->C 会形成环而被跳过
+	e := Election{Candidates: candidates, Ballots: ballots(
+		"ABC", "ABC", "ABC", "BCA", "BCA", "CAB", "CAB", "CAB", "CAB")}
+	winners, ok := RankedPairsWinners(e)
+	require.True(t, ok)
+	assert.Equal(t, []string{"Charlie"}, winners)
+
+	// 完全对称的循环：三个数对强度相同，锁定顺序决定胜者
+	e = Election{Candidates: candidates, Ballots: ballots("ABC", "BCA", "CAB")}
+	winners, ok = RankedPairsWinners(e)
+	require.True(t, ok)
+	assert.Equal(t, candidates, winners)
+
+	// Alice 与 Bob 完全打平，没有唯一的源点
+	e = Election{Candidates: candidates, Ballots: ballots("ABC", "BAC")}
+	_, ok = RankedPairsWinners(e)
+	assert.False(t, ok)
+}
+
+func TestGenerateElections(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		e := GeneratePluralityElection(rng)
+		assert.NotEmpty(t, e.Ballots)
+		assert.NotEmpty(t, PluralityWinners(e))
+
+		e = GenerateRunoffElection(rng)
+		assert.LessOrEqual(t, len(e.Candidates), MaxCandidates)
+		for _, ballot := range e.Ballots {
+			assert.ElementsMatch(t, e.Candidates, ballot)
+		}
+		assert.NotEmpty(t, RunoffWinners(e))
+
+		e = GenerateTidemanElection(rng)
+		winners, ok := RankedPairsWinners(e)
+		assert.True(t, ok)
+		assert.NotEmpty(t, winners)
+	}
+}
+
+func TestParseElectionOutput(t *testing.T) {
+	output := "Number of voters: Vote: Vote: Invalid vote.\r\nVote: Alice\r\nBob\r\n"
+	assert.Equal(t, []string{"Alice", "Bob"}, ParseElectionOutput(output))
+
+	output = "Number of voters: Rank 1: Rank 2: \nRank 1: Rank 2: \nCharlie\n"
+	assert.Equal(t, []string{"Charlie"}, ParseElectionOutput(output))
+}
@@ -0,0 +1,588 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FiftyvilleSeedFile 保存随机 Fiftyville 谜题的种子，由学生放在提交目录中
+const FiftyvilleSeedFile = "fiftyville.seed"
+
+// fiftyvilleYear 与 CS50 原版相同，盗窃发生在 2023 年 7 月
+const (
+	fiftyvilleYear  = 2023
+	fiftyvilleMonth = 7
+)
+
+// FiftyvilleKey 返回 grader 持有的密钥 (BOOTLLM_FIFTYVILLE_KEY)，设置后即为每个学生发放随机谜题
+func FiftyvilleKey() string {
+	return os.Getenv("BOOTLLM_FIFTYVILLE_KEY")
+}
+
+// FiftyvilleSeed 由学生身份派生谜题种子：HMAC-SHA256(key, student) 的前 8 字节
+// 不知道密钥就算不出别人的种子，抄来的种子也与自己的身份对不上
+func FiftyvilleSeed(key, student string) int64 {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(student))
+	return int64(binary.BigEndian.Uint64(mac.Sum(nil)) >> 1)
+}
+
+// ReadFiftyvilleSeed 读取目录中的种子文件；文件不存在时 ok 为 false
+func ReadFiftyvilleSeed(dir string) (seed int64, ok bool, err error) {
+	content, err := os.ReadFile(filepath.Join(dir, FiftyvilleSeedFile))
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	seed, err = strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid %s: %v", FiftyvilleSeedFile, err)
+	}
+	return seed, true, nil
+}
+
+// fiftyvilleSchema 与 CS50 fiftyville.db 的表结构相同
+const fiftyvilleSchema = `
+CREATE TABLE crime_scene_reports (id INTEGER, year INTEGER, month INTEGER, day INTEGER, street TEXT, description TEXT, PRIMARY KEY(id));
+CREATE TABLE interviews (id INTEGER, name TEXT, year INTEGER, month INTEGER, day INTEGER, transcript TEXT, PRIMARY KEY(id));
+CREATE TABLE atm_transactions (id INTEGER, account_number INTEGER, year INTEGER, month INTEGER, day INTEGER, atm_location TEXT, transaction_type TEXT, amount INTEGER, PRIMARY KEY(id));
+CREATE TABLE bank_accounts (account_number INTEGER, person_id INTEGER, creation_year INTEGER, FOREIGN KEY(person_id) REFERENCES people(id));
+CREATE TABLE airports (id INTEGER, abbreviation TEXT, full_name TEXT, city TEXT, PRIMARY KEY(id));
+CREATE TABLE flights (id INTEGER, origin_airport_id INTEGER, destination_airport_id INTEGER, year INTEGER, month INTEGER, day INTEGER, hour INTEGER, minute INTEGER, PRIMARY KEY(id), FOREIGN KEY(origin_airport_id) REFERENCES airports(id), FOREIGN KEY(destination_airport_id) REFERENCES airports(id));
+CREATE TABLE passengers (flight_id INTEGER, passport_number INTEGER, seat TEXT, FOREIGN KEY(flight_id) REFERENCES flights(id));
+CREATE TABLE phone_calls (id INTEGER, caller TEXT, receiver TEXT, year INTEGER, month INTEGER, day INTEGER, duration INTEGER, PRIMARY KEY(id));
+CREATE TABLE people (id INTEGER, name TEXT, phone_number TEXT, passport_number INTEGER, license_plate TEXT, PRIMARY KEY(id));
+CREATE TABLE bakery_security_logs (id INTEGER, year INTEGER, month INTEGER, day INTEGER, hour INTEGER, minute INTEGER, activity TEXT, license_plate TEXT, PRIMARY KEY(id));
+`
+
+var (
+	fiftyvilleFirstNames = []string{
+		"Ada", "Beatrix", "Cedric", "Delia", "Elliot", "Fiona", "Gideon", "Hazel", "Isaac", "Jasper",
+		"Keira", "Leon", "Mabel", "Nolan", "Opal", "Percy", "Quinn", "Rosalind", "Silas", "Thea",
+		"Ulric", "Vera", "Wallace", "Xenia", "Yusuf", "Zelda", "Amos", "Bianca", "Cyrus", "Dahlia",
+		"Ezra", "Flora", "Gus", "Harriet", "Ivo", "Juniper", "Kit", "Lucinda", "Milo", "Nadia",
+	}
+	fiftyvilleLastNames = []string{
+		"Abernathy", "Blackwood", "Castellano", "Dunmore", "Everly", "Fairbanks", "Galloway", "Hargrove",
+		"Ingram", "Jessup", "Kingsley", "Lockhart", "Merriweather", "Northcott", "Oakes", "Pemberton",
+		"Quimby", "Ravenscroft", "Sterling", "Thornbury",
+	}
+	fiftyvilleStreets = []string{
+		"Humphrey Street", "Leggett Street", "Fifer Street", "Chamberlin Street", "Daboin Sanchez Drive",
+		"Zlatkovich Street", "Widenius Street", "Boyce Avenue", "Carvalho Road", "Thompson Street",
+	}
+	// fiftyvilleAirports 的第一项是 Fiftyville 本地机场，其余是可能的目的地
+	fiftyvilleAirports = [][3]string{
+		{"CSF", "Fiftyville Regional Airport", "Fiftyville"},
+		{"ORD", "O'Hare International Airport", "Chicago"},
+		{"LGA", "LaGuardia Airport", "New York City"},
+		{"BOS", "Logan International Airport", "Boston"},
+		{"SFO", "San Francisco International Airport", "San Francisco"},
+		{"LAX", "Los Angeles International Airport", "Los Angeles"},
+		{"HND", "Tokyo International Airport", "Tokyo"},
+		{"CDG", "Charles de Gaulle Airport", "Paris"},
+		{"DFS", "Dallas/Fort Worth International Airport", "Dallas"},
+		{"DXB", "Dubai International Airport", "Dubai"},
+		{"PEK", "Beijing Capital International Airport", "Beijing"},
+		{"MIA", "Miami International Airport", "Miami"},
+		{"DEL", "Indira Gandhi International Airport", "Delhi"},
+	}
+	fiftyvilleIncidents = []string{
+		"Littering took place at %s. No known witnesses.",
+		"Vandalism took place at %s. A mailbox was spray-painted overnight.",
+		"Theft of a bicycle took place at %s. The owner reported it missing at noon.",
+		"Shoplifting took place at %s. Two witnesses gave statements.",
+		"Money laundering took place at %s. No known witnesses.",
+		"Credit card fraud took place at %s. The victim noticed unfamiliar charges.",
+		"Expired parking meter took place at %s. A ticket was issued.",
+		"Burglary took place at %s. Interviews were conducted with the neighbors.",
+	}
+	fiftyvilleSmallTalk = []string{
+		"I was out walking my dog all morning and didn't notice anything unusual.",
+		"The weather was lovely today, so I spent most of the afternoon in the park.",
+		"I've lived here for twenty years and never seen the town this busy.",
+		"My neighbor keeps parking in front of my driveway. Can somebody do something about it?",
+		"I heard some shouting late at night, but it was probably just kids.",
+		"I was at the library until closing and went straight home afterwards.",
+	}
+)
+
+// fiftyvillePerson 是数据库中的一个人；空字符串或 0 表示该项为 NULL
+type fiftyvillePerson struct {
+	id       int
+	name     string
+	phone    string
+	passport int64
+	plate    string
+	account  int64
+}
+
+// 谜题的四条线索：离开面包店停车场、在 ATM 取钱、短电话、明天最早的航班
+const (
+	clueExit = iota
+	clueATM
+	clueCall
+	clueFlight
+	clueCount
+)
+
+// fiftyvilleRow 是待插入的一行
+type fiftyvilleRow struct {
+	table  string
+	values []any
+}
+
+// FiftyvilleMystery 是由种子生成的一个 Fiftyville 谜题及其答案
+type FiftyvilleMystery struct {
+	Seed       int64
+	Thief      string
+	Accomplice string
+	City       string
+
+	// 以下是谜题的细节，供测试核对
+	day, hour, minute       int
+	bakeryStreet, atmStreet string
+
+	rows []fiftyvilleRow
+}
+
+// fiftyvilleBuilder 生成谜题时的状态
+type fiftyvilleBuilder struct {
+	rng    *rand.Rand
+	m      *FiftyvilleMystery
+	people []*fiftyvillePerson
+	// clues[i][c] 表示第 i 个人满足第 c 条线索
+	clues   [][clueCount]bool
+	thief   int
+	partner int
+	ids     map[string]int
+}
+
+// GenerateFiftyville 由种子生成一个与 CS50 原版结构相同的谜题：
+// 小偷、同伙和目的地随机选取，各表中的记录彼此一致，且四条线索恰好锁定一人
+func GenerateFiftyville(seed int64) *FiftyvilleMystery {
+	b := &fiftyvilleBuilder{
+		rng: rand.New(rand.NewSource(seed)),
+		m:   &FiftyvilleMystery{Seed: seed},
+		ids: map[string]int{},
+	}
+	b.m.day = 1 + b.rng.Intn(27)
+	b.m.hour = 9 + b.rng.Intn(3)
+	b.m.minute = 10 + b.rng.Intn(31)
+	streets := append([]string(nil), fiftyvilleStreets...)
+	b.rng.Shuffle(len(streets), func(i, j int) { streets[i], streets[j] = streets[j], streets[i] })
+	b.m.bakeryStreet, b.m.atmStreet = streets[0], streets[1]
+
+	b.generatePeople()
+	b.generateReports(streets)
+	b.generateInterviews()
+	b.generateSecurityLogs()
+	b.generateATM()
+	b.generateCalls()
+	b.generateFlights()
+	return b.m
+}
+
+// add 追加一行；id 为 true 时在最前面加上该表的下一个 id
+func (b *fiftyvilleBuilder) add(table string, id bool, values ...any) {
+	if id {
+		b.ids[table]++
+		values = append([]any{b.ids[table]}, values...)
+	}
+	b.m.rows = append(b.m.rows, fiftyvilleRow{table, values})
+}
+
+// nullable 把空值转换为 NULL
+func nullable[T comparable](v T) any {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return v
+}
+
+// generatePeople 生成约 160 人，选出小偷、同伙和干扰者，并分配线索
+func (b *fiftyvilleBuilder) generatePeople() {
+	const n = 160
+	names := b.rng.Perm(len(fiftyvilleFirstNames) * len(fiftyvilleLastNames))[:n]
+	used := map[string]bool{}
+	unique := func(gen func() string) string {
+		for {
+			if v := gen(); !used[v] {
+				used[v] = true
+				return v
+			}
+		}
+	}
+	const plateChars = "ABCDEFGHJKLMNPRSTUVWXYZ0123456789"
+
+	for i, k := range names {
+		p := &fiftyvillePerson{
+			id:   i + 1,
+			name: fiftyvilleFirstNames[k%len(fiftyvilleFirstNames)] + " " + fiftyvilleLastNames[k/len(fiftyvilleFirstNames)],
+		}
+		p.phone = unique(func() string { return fmt.Sprintf("(%03d) 555-%04d", 200+b.rng.Intn(800), b.rng.Intn(10000)) })
+		p.passport, _ = strconv.ParseInt(unique(func() string { return strconv.FormatInt(1e9+b.rng.Int63n(9e9), 10) }), 10, 64)
+		p.plate = unique(func() string {
+			var s strings.Builder
+			for range 7 {
+				s.WriteByte(plateChars[b.rng.Intn(len(plateChars))])
+			}
+			return s.String()
+		})
+		p.account, _ = strconv.ParseInt(unique(func() string { return strconv.Itoa(10000000 + b.rng.Intn(90000000)) }), 10, 64)
+		b.people = append(b.people, p)
+	}
+	b.clues = make([][clueCount]bool, n)
+
+	// 前 17 人是谜题中的关键人物：小偷、同伙、3 名证人和 12 名干扰者，各项信息齐全
+	roles := b.rng.Perm(n)
+	b.thief, b.partner = roles[0], roles[1]
+	b.m.Thief, b.m.Accomplice = b.people[b.thief].name, b.people[b.partner].name
+	for c := range clueCount {
+		b.clues[b.thief][c] = true
+	}
+	decoys := roles[5:17]
+	// 前 4 名干扰者各只差一条线索，因此每条线索都不可或缺
+	for i, d := range decoys[:clueCount] {
+		for c := range clueCount {
+			b.clues[d][c] = c != i
+		}
+	}
+	for _, d := range decoys[clueCount:] {
+		b.clues[d][b.rng.Intn(clueCount)] = true
+		b.clues[d][b.rng.Intn(clueCount)] = true
+	}
+
+	// 其余的人有部分信息缺失
+	for _, i := range roles[17:] {
+		p := b.people[i]
+		if b.rng.Intn(10) == 0 {
+			p.phone = ""
+		}
+		if b.rng.Intn(8) == 0 {
+			p.passport = 0
+		}
+		if b.rng.Intn(6) == 0 {
+			p.plate = ""
+		}
+		if b.rng.Intn(10) == 0 {
+			p.account = 0
+		}
+	}
+
+	for _, p := range b.people {
+		b.add("people", false, p.id, p.name, nullable(p.phone), nullable(p.passport), nullable(p.plate))
+	}
+	for _, p := range b.people {
+		if p.account != 0 {
+			b.add("bank_accounts", false, p.account, p.id, 2000+b.rng.Intn(23))
+		}
+	}
+}
+
+// suspects 返回满足线索 c 的人
+func (b *fiftyvilleBuilder) suspects(c int) []int {
+	var s []int
+	for i := range b.people {
+		if b.clues[i][c] {
+			s = append(s, i)
+		}
+	}
+	return s
+}
+
+// pick 随机选取一个满足 ok 的人
+func (b *fiftyvilleBuilder) pick(ok func(i int, p *fiftyvillePerson) bool) int {
+	for {
+		if i := b.rng.Intn(len(b.people)); ok(i, b.people[i]) {
+			return i
+		}
+	}
+}
+
+// days 返回本月的每一天
+func (b *fiftyvilleBuilder) days() []int {
+	days := make([]int, 28)
+	for i := range days {
+		days[i] = i + 1
+	}
+	return days
+}
+
+// generateReports 生成犯罪现场报告，盗窃当天的报告给出时间和地点
+func (b *fiftyvilleBuilder) generateReports(streets []string) {
+	m := b.m
+	for _, day := range b.days() {
+		count := 1 + b.rng.Intn(3)
+		for range count {
+			street := streets[1+b.rng.Intn(len(streets)-1)]
+			b.add("crime_scene_reports", true, fiftyvilleYear, fiftyvilleMonth, day, street,
+				fmt.Sprintf(fiftyvilleIncidents[b.rng.Intn(len(fiftyvilleIncidents))], street))
+		}
+		if day == m.day {
+			b.add("crime_scene_reports", true, fiftyvilleYear, fiftyvilleMonth, day, m.bakeryStreet,
+				fmt.Sprintf("Theft of the CS50 duck took place at %d:%02dam at the %s bakery. "+
+					"Interviews were conducted today with three witnesses who were present at the time – "+
+					"each of their interview transcripts mentions the bakery.", m.hour, m.minute, m.bakeryStreet))
+		}
+	}
+}
+
+// generateInterviews 生成访谈记录，盗窃当天的三名证人各给出线索
+func (b *fiftyvilleBuilder) generateInterviews() {
+	m := b.m
+	witnesses := []string{
+		"Sometime within ten minutes of the theft, I saw the thief get into a car in the bakery parking lot and drive away. " +
+			"If you have security footage from the bakery parking lot, you might want to look for cars that left the parking lot in that time frame.",
+		fmt.Sprintf("I don't know the thief's name, but it was someone I recognized. Earlier this morning, before I arrived at the bakery, "+
+			"I was walking by the ATM on %s and saw the thief there withdrawing some money.", m.atmStreet),
+		"As the thief was leaving the bakery, they called someone who talked to them for less than a minute. " +
+			"In the call, I heard the thief say that they were planning to take the earliest flight out of Fiftyville tomorrow. " +
+			"The thief then asked the person on the other end of the phone to purchase the flight ticket.",
+	}
+	chatter := func() string { return fiftyvilleSmallTalk[b.rng.Intn(len(fiftyvilleSmallTalk))] }
+	anyone := func(int, *fiftyvillePerson) bool { return true }
+
+	for _, day := range b.days() {
+		var transcripts []string
+		for range b.rng.Intn(3) {
+			transcripts = append(transcripts, chatter())
+		}
+		if day == m.day {
+			transcripts = append(transcripts, witnesses...)
+			b.rng.Shuffle(len(transcripts), func(i, j int) { transcripts[i], transcripts[j] = transcripts[j], transcripts[i] })
+		}
+		for _, t := range transcripts {
+			// 证人不会是小偷或同伙
+			who := b.pick(func(i int, _ *fiftyvillePerson) bool { return i != b.thief && i != b.partner })
+			if !strings.Contains(t, "bakery") {
+				who = b.pick(anyone)
+			}
+			b.add("interviews", true, b.people[who].name, fiftyvilleYear, fiftyvilleMonth, day, t)
+		}
+	}
+}
+
+// generateSecurityLogs 生成面包店停车场记录：线索为盗窃后十分钟内离开
+func (b *fiftyvilleBuilder) generateSecurityLogs() {
+	m := b.m
+	theft := m.hour*60 + m.minute
+	type entry struct {
+		time     int
+		activity string
+		plate    string
+	}
+
+	for _, day := range b.days() {
+		var entries []entry
+		visit := func(p *fiftyvillePerson, enter, exit int) {
+			entries = append(entries, entry{enter, "entrance", p.plate}, entry{exit, "exit", p.plate})
+		}
+		// outside 返回不在盗窃时间附近的时刻，窗口边界前后都留有余量
+		outside := func() int {
+			for {
+				t := 7*60 + b.rng.Intn(11*60)
+				if day != m.day || t < theft-3 || t > theft+13 {
+					return t
+				}
+			}
+		}
+
+		if day == m.day {
+			for _, i := range b.suspects(clueExit) {
+				exit := theft + 1 + b.rng.Intn(9)
+				visit(b.people[i], 7*60+b.rng.Intn(theft-7*60-15), exit)
+			}
+		}
+		for range 6 + b.rng.Intn(8) {
+			p := b.people[b.pick(func(i int, p *fiftyvillePerson) bool {
+				return p.plate != "" && (day != m.day || !b.clues[i][clueExit])
+			})]
+			t1, t2 := outside(), outside()
+			if t1 == t2 {
+				continue
+			}
+			visit(p, min(t1, t2), max(t1, t2))
+		}
+
+		sort.SliceStable(entries, func(i, j int) bool { return entries[i].time < entries[j].time })
+		for _, e := range entries {
+			b.add("bakery_security_logs", true, fiftyvilleYear, fiftyvilleMonth, day, e.time/60, e.time%60, e.activity, e.plate)
+		}
+	}
+}
+
+// generateATM 生成 ATM 交易：线索为盗窃当天在指定 ATM 取钱
+func (b *fiftyvilleBuilder) generateATM() {
+	m := b.m
+	amount := func() int { return 5 * (2 + b.rng.Intn(20)) }
+	for _, day := range b.days() {
+		type tx struct {
+			account  int64
+			location string
+			kind     string
+		}
+		var txs []tx
+		if day == m.day {
+			for _, i := range b.suspects(clueATM) {
+				txs = append(txs, tx{b.people[i].account, m.atmStreet, "withdraw"})
+			}
+		}
+		for range 5 + b.rng.Intn(6) {
+			i := b.pick(func(_ int, p *fiftyvillePerson) bool { return p.account != 0 })
+			location := fiftyvilleStreets[b.rng.Intn(len(fiftyvilleStreets))]
+			kind := []string{"withdraw", "deposit"}[b.rng.Intn(2)]
+			// 当天在该 ATM 取钱的只有满足线索的人；其他人在那里只存钱
+			if day == m.day && location == m.atmStreet && !b.clues[i][clueATM] {
+				kind = "deposit"
+			}
+			txs = append(txs, tx{b.people[i].account, location, kind})
+		}
+		b.rng.Shuffle(len(txs), func(i, j int) { txs[i], txs[j] = txs[j], txs[i] })
+		for _, t := range txs {
+			b.add("atm_transactions", true, t.account, fiftyvilleYear, fiftyvilleMonth, day, t.location, t.kind, amount())
+		}
+	}
+}
+
+// generateCalls 生成通话记录：线索为盗窃当天不到一分钟的通话，小偷打给的是同伙
+func (b *fiftyvilleBuilder) generateCalls() {
+	m := b.m
+	for _, day := range b.days() {
+		type call struct {
+			caller, receiver string
+			duration         int
+		}
+		var calls []call
+		if day == m.day {
+			for _, i := range b.suspects(clueCall) {
+				receiver := b.partner
+				if i != b.thief {
+					receiver = b.pick(func(j int, p *fiftyvillePerson) bool { return p.phone != "" && j != i && j != b.partner })
+				}
+				calls = append(calls, call{b.people[i].phone, b.people[receiver].phone, 20 + b.rng.Intn(40)})
+			}
+		}
+		for range 8 + b.rng.Intn(8) {
+			caller := b.pick(func(i int, p *fiftyvillePerson) bool { return p.phone != "" && (day != m.day || i != b.thief) })
+			receiver := b.pick(func(i int, p *fiftyvillePerson) bool { return p.phone != "" && i != caller })
+			duration := 70 + b.rng.Intn(530)
+			// 其他天的短电话不影响线索
+			if day != m.day && b.rng.Intn(3) == 0 {
+				duration = 10 + b.rng.Intn(50)
+			}
+			calls = append(calls, call{b.people[caller].phone, b.people[receiver].phone, duration})
+		}
+		b.rng.Shuffle(len(calls), func(i, j int) { calls[i], calls[j] = calls[j], calls[i] })
+		for _, c := range calls {
+			b.add("phone_calls", true, c.caller, c.receiver, fiftyvilleYear, fiftyvilleMonth, day, c.duration)
+		}
+	}
+}
+
+// generateFlights 生成机场、航班和乘客：线索为第二天从 Fiftyville 出发的最早航班
+func (b *fiftyvilleBuilder) generateFlights() {
+	m := b.m
+	airports := b.rng.Perm(len(fiftyvilleAirports))
+	airportID := make([]int, len(fiftyvilleAirports))
+	for id, k := range airports {
+		airportID[k] = id + 1
+	}
+	for _, k := range airports {
+		a := fiftyvilleAirports[k]
+		b.add("airports", true, a[0], a[1], a[2])
+	}
+	destination := 1 + b.rng.Intn(len(fiftyvilleAirports)-1)
+	m.City = fiftyvilleAirports[destination][2]
+
+	type flight struct {
+		origin, dest, day, time int
+		passengers              []int
+	}
+	var flights []*flight
+	elsewhere := func() int { return 1 + b.rng.Intn(len(fiftyvilleAirports)-1) }
+	for _, day := range b.days() {
+		earliest := 6 * 60
+		if day == m.day+1 {
+			earliest = 7*60 + b.rng.Intn(100)
+			flights = append(flights, &flight{origin: 0, dest: destination, day: day, time: earliest, passengers: b.suspects(clueFlight)})
+			earliest += 30
+		}
+		for range 2 + b.rng.Intn(3) {
+			flights = append(flights, &flight{origin: 0, dest: elsewhere(), day: day, time: earliest + b.rng.Intn(20*60-earliest)})
+		}
+		for range 2 + b.rng.Intn(3) {
+			flights = append(flights, &flight{origin: elsewhere(), dest: 0, day: day, time: 6*60 + b.rng.Intn(14*60)})
+		}
+	}
+	b.rng.Shuffle(len(flights), func(i, j int) { flights[i], flights[j] = flights[j], flights[i] })
+
+	for _, f := range flights {
+		b.add("flights", true, airportID[f.origin], airportID[f.dest], fiftyvilleYear, fiftyvilleMonth, f.day, f.time/60, f.time%60)
+		id := b.ids["flights"]
+
+		// 其他乘客都不满足航班线索，也不是同伙（同伙留在 Fiftyville）；
+		// 最早航班上的其他乘客不满足任何线索
+		earliest := f.passengers != nil
+		seen := map[int]bool{}
+		for _, i := range f.passengers {
+			seen[i] = true
+		}
+		for range 2 + b.rng.Intn(6) {
+			i := b.pick(func(i int, p *fiftyvillePerson) bool {
+				if earliest && b.clues[i] != [clueCount]bool{} {
+					return false
+				}
+				return p.passport != 0 && !b.clues[i][clueFlight] && i != b.partner && i != b.thief
+			})
+			if !seen[i] {
+				seen[i] = true
+				f.passengers = append(f.passengers, i)
+			}
+		}
+		b.rng.Shuffle(len(f.passengers), func(i, j int) { f.passengers[i], f.passengers[j] = f.passengers[j], f.passengers[i] })
+		for k, i := range f.passengers {
+			b.add("passengers", false, id, b.people[i].passport, fmt.Sprintf("%d%c", k/4+1, 'A'+k%4))
+		}
+	}
+}
+
+// WriteDB 把谜题写入新的 SQLite 数据库 path（已存在的文件会被覆盖）
+func (m *FiftyvilleMystery) WriteDB(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if _, err := tx.Exec(fiftyvilleSchema); err != nil {
+		return fmt.Errorf("failed to create fiftyville schema: %v", err)
+	}
+	for _, row := range m.rows {
+		placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(row.values)), ", ")
+		if _, err := tx.Exec("INSERT INTO "+row.table+" VALUES ("+placeholders+")", row.values...); err != nil {
+			return fmt.Errorf("failed to insert into %s: %v", row.table, err)
+		}
+	}
+	return tx.Commit()
+}
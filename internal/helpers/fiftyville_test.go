@@ -0,0 +1,106 @@
+package helpers
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fiftyvilleClues 是四条线索对应的子查询，返回满足线索的人的 id
+func fiftyvilleClues(m *FiftyvilleMystery) []string {
+	day := fmt.Sprintf("year = 2023 AND month = 7 AND day = %d", m.day)
+	return []string{
+		fmt.Sprintf(`SELECT people.id FROM people JOIN bakery_security_logs ON bakery_security_logs.license_plate = people.license_plate
+			WHERE %s AND hour = %d AND minute BETWEEN %d AND %d AND activity = 'exit'`, day, m.hour, m.minute, m.minute+10),
+		fmt.Sprintf(`SELECT person_id FROM bank_accounts JOIN atm_transactions ON atm_transactions.account_number = bank_accounts.account_number
+			WHERE %s AND atm_location = '%s' AND transaction_type = 'withdraw'`, day, m.atmStreet),
+		fmt.Sprintf(`SELECT people.id FROM people JOIN phone_calls ON caller = phone_number WHERE %s AND duration < 60`, day),
+		fmt.Sprintf(`SELECT people.id FROM people JOIN passengers ON passengers.passport_number = people.passport_number
+			WHERE flight_id = (SELECT flights.id FROM flights JOIN airports ON airports.id = origin_airport_id
+				WHERE city = 'Fiftyville' AND year = 2023 AND month = 7 AND day = %d ORDER BY hour, minute LIMIT 1)`, m.day+1),
+	}
+}
+
+func TestGenerateFiftyville(t *testing.T) {
+	for _, seed := range []int64{1, 2, 42, 2023} {
+		m := GenerateFiftyville(seed)
+		path := filepath.Join(t.TempDir(), "fiftyville.db")
+		require.NoError(t, m.WriteDB(path))
+		db, err := sql.Open("sqlite3", path)
+		require.NoError(t, err)
+
+		// 四条线索的交集恰好是小偷，去掉任意一条都不再唯一
+		clues := fiftyvilleClues(m)
+		names := func(clues []string) [][]any {
+			query := "SELECT name FROM people WHERE id IN (" + clues[0] + ")"
+			for _, c := range clues[1:] {
+				query += " AND id IN (" + c + ")"
+			}
+			rows, err := QueryRows(db, query)
+			require.NoError(t, err)
+			return rows
+		}
+		assert.Equal(t, SingleColumn([]string{m.Thief}), names(clues), "seed %d", seed)
+		for i := range clues {
+			rest := append(append([]string(nil), clues[:i]...), clues[i+1:]...)
+			assert.Greater(t, len(names(rest)), 1, "seed %d without clue %d", seed, i)
+		}
+
+		// 同伙是小偷当天不到一分钟通话的接听者，目的地是最早航班的城市
+		rows, err := QueryRows(db, fmt.Sprintf(`SELECT r.name FROM phone_calls
+			JOIN people c ON c.phone_number = caller JOIN people r ON r.phone_number = receiver
+			WHERE c.name = '%s' AND year = 2023 AND month = 7 AND day = %d AND duration < 60`, m.Thief, m.day))
+		require.NoError(t, err)
+		assert.Equal(t, [][]any{{m.Accomplice}}, rows)
+		rows, err = QueryRows(db, fmt.Sprintf(`SELECT city FROM airports WHERE id = (SELECT destination_airport_id FROM flights
+			WHERE origin_airport_id = (SELECT id FROM airports WHERE city = 'Fiftyville') AND year = 2023 AND month = 7 AND day = %d
+			ORDER BY hour, minute LIMIT 1)`, m.day+1))
+		require.NoError(t, err)
+		assert.Equal(t, [][]any{{m.City}}, rows)
+
+		rows, err = QueryRows(db, fmt.Sprintf(`SELECT description FROM crime_scene_reports
+			WHERE year = 2023 AND month = 7 AND day = %d AND street = '%s'`, m.day, m.bakeryStreet))
+		require.NoError(t, err)
+		require.Len(t, rows, 1)
+		assert.Contains(t, rows[0][0], fmt.Sprintf("%d:%02dam", m.hour, m.minute))
+		db.Close()
+	}
+}
+
+func TestGenerateFiftyvilleDeterministic(t *testing.T) {
+	a, b := GenerateFiftyville(7), GenerateFiftyville(7)
+	assert.Equal(t, a, b)
+
+	thieves := map[string]bool{}
+	for seed := range int64(10) {
+		thieves[GenerateFiftyville(seed).Thief] = true
+	}
+	assert.Greater(t, len(thieves), 5)
+}
+
+func TestFiftyvilleSeedFile(t *testing.T) {
+	dir := t.TempDir()
+	_, ok, err := ReadFiftyvilleSeed(dir)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, FiftyvilleSeedFile), []byte("12345\n"), 0644))
+	seed, ok, err := ReadFiftyvilleSeed(dir)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int64(12345), seed)
+}
+
+func TestFiftyvilleSeed(t *testing.T) {
+	seed := FiftyvilleSeed("grader-key", "alice")
+	assert.Equal(t, seed, FiftyvilleSeed("grader-key", "alice"))
+	assert.GreaterOrEqual(t, seed, int64(0))
+	assert.NotEqual(t, seed, FiftyvilleSeed("grader-key", "bob"))
+	assert.NotEqual(t, seed, FiftyvilleSeed("other-key", "alice"))
+}
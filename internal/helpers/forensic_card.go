@@ -0,0 +1,153 @@
+package helpers
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bootllm/tester-utils/random"
+)
+
+// RecoverBlockSize 是 FAT 文件系统的块大小，JPEG 总是从块的开头开始
+const RecoverBlockSize = 512
+
+// RecoveredJPEG 是生成的存储卡中的一张 JPEG
+type RecoveredJPEG struct {
+	Name string
+	// Offset 是在存储卡中的起始字节
+	Offset int
+	// Data 是 recover 应写出的完整内容：从签名所在的块直到下一个签名块（或文件末尾）
+	Data []byte
+}
+
+// ForensicCard 是生成的类 FAT 原始镜像及其中应恢复出的文件
+type ForensicCard struct {
+	Data  []byte
+	JPEGs []RecoveredJPEG
+}
+
+// isJPEGSignature 判断块是否以 0xff 0xd8 0xff 0xeN 开头
+func isJPEGSignature(block []byte) bool {
+	return len(block) >= 4 && block[0] == 0xff && block[1] == 0xd8 && block[2] == 0xff && block[3]&0xf0 == 0xe0
+}
+
+// randomBlock 生成一个不以 JPEG 签名开头的随机块
+// decoy 为 true 时，在块的中间放一个签名，正确的程序只在块首检测签名
+func randomBlock(decoy bool) []byte {
+	block := make([]byte, RecoverBlockSize)
+	for i := range block {
+		block[i] = byte(random.RandomInt(0, 256))
+	}
+	if isJPEGSignature(block) {
+		block[0] = 0
+	}
+	if decoy {
+		at := random.RandomInt(1, RecoverBlockSize-4)
+		copy(block[at:], []byte{0xff, 0xd8, 0xff, 0xe0})
+	}
+	return block
+}
+
+// GenerateForensicCard 生成包含 count 张 JPEG 的存储卡：
+//   - 开头有若干块垃圾数据（不含块首签名，但可能含块中间的假签名）
+//   - 每张 JPEG 占整数个块，最后一块的剩余部分以 0 填充
+//   - 最后一张 JPEG 之后有若干全 0 的空闲块，按 recover 的规则属于最后一张
+func GenerateForensicCard(count int) *ForensicCard {
+	var card bytes.Buffer
+	for i := random.RandomInt(1, 9); i > 0; i-- {
+		card.Write(randomBlock(random.RandomInt(0, 4) == 0))
+	}
+
+	c := &ForensicCard{}
+	for n := 0; n < count; n++ {
+		start := card.Len()
+		size := random.RandomInt(RecoverBlockSize/2, RecoverBlockSize*12)
+
+		body := make([]byte, 0, size+RecoverBlockSize)
+		body = append(body, 0xff, 0xd8, 0xff, byte(0xe0|random.RandomInt(0, 16)))
+		for len(body) < size {
+			block := randomBlock(random.RandomInt(0, 8) == 0)
+			if len(body)%RecoverBlockSize == 0 {
+				body = append(body, block...)
+			} else {
+				// 补齐第一个块，避免后续块首意外出现签名
+				body = append(body, block[:RecoverBlockSize-len(body)%RecoverBlockSize]...)
+			}
+		}
+		body = body[:size]
+		body = append(body, 0xff, 0xd9)
+		// 最后一块的剩余部分（slack space）填 0
+		if rem := len(body) % RecoverBlockSize; rem != 0 {
+			body = append(body, make([]byte, RecoverBlockSize-rem)...)
+		}
+		card.Write(body)
+		c.JPEGs = append(c.JPEGs, RecoveredJPEG{Name: fmt.Sprintf("%03d.jpg", n), Offset: start})
+	}
+
+	card.Write(make([]byte, RecoverBlockSize*random.RandomInt(0, 6)))
+	c.Data = card.Bytes()
+
+	for i := range c.JPEGs {
+		end := len(c.Data)
+		if i+1 < len(c.JPEGs) {
+			end = c.JPEGs[i+1].Offset
+		}
+		c.JPEGs[i].Data = c.Data[c.JPEGs[i].Offset:end]
+	}
+	return c
+}
+
+// CheckRecovered 检查 dir 中恢复出的 ###.jpg，列出缺失、多余、被截断和内容不符的文件及其在卡中的位置
+func (c *ForensicCard) CheckRecovered(dir string) error {
+	var problems []string
+	for _, jpeg := range c.JPEGs {
+		data, err := os.ReadFile(filepath.Join(dir, jpeg.Name))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s is missing (the JPEG starts at byte %d, block %d of the card)",
+				jpeg.Name, jpeg.Offset, jpeg.Offset/RecoverBlockSize))
+			continue
+		}
+		if bytes.Equal(data, jpeg.Data) {
+			continue
+		}
+		at := firstDifference(data, jpeg.Data)
+		switch {
+		case at == len(data) && len(data) < len(jpeg.Data):
+			problems = append(problems, fmt.Sprintf("%s is truncated: %d of %d bytes (stops at card byte %d)",
+				jpeg.Name, len(data), len(jpeg.Data), jpeg.Offset+len(data)))
+		case at == len(jpeg.Data):
+			problems = append(problems, fmt.Sprintf("%s has %d extra bytes after card byte %d (it should end where the next JPEG begins)",
+				jpeg.Name, len(data)-len(jpeg.Data), jpeg.Offset+len(jpeg.Data)))
+		default:
+			problems = append(problems, fmt.Sprintf("%s differs at byte %d (card byte %d)",
+				jpeg.Name, at, jpeg.Offset+at))
+		}
+	}
+
+	// 多余的文件：编号超出 JPEG 数量的 ###.jpg
+	matches, _ := filepath.Glob(filepath.Join(dir, "[0-9][0-9][0-9].jpg"))
+	for _, m := range matches {
+		var n int
+		if _, err := fmt.Sscanf(filepath.Base(m), "%03d.jpg", &n); err == nil && n >= len(c.JPEGs) {
+			problems = append(problems, fmt.Sprintf("%s is extra: the card only contains %d JPEGs", filepath.Base(m), len(c.JPEGs)))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// firstDifference 返回两段数据第一个不同的字节位置，一段是另一段的前缀时返回较短的长度
+func firstDifference(a, b []byte) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
@@ -0,0 +1,65 @@
+package helpers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootllm/tester-utils/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recoverBlocks 按 recover 的规则切分存储卡：每个以签名开头的块开始一个新文件
+func recoverBlocks(card []byte) [][]byte {
+	var files [][]byte
+	for off := 0; off+RecoverBlockSize <= len(card); off += RecoverBlockSize {
+		block := card[off : off+RecoverBlockSize]
+		if isJPEGSignature(block) {
+			files = append(files, nil)
+		}
+		if len(files) > 0 {
+			files[len(files)-1] = append(files[len(files)-1], block...)
+		}
+	}
+	return files
+}
+
+func TestGenerateForensicCard(t *testing.T) {
+	random.Init()
+	for i := 0; i < 5; i++ {
+		card := GenerateForensicCard(10)
+		assert.Zero(t, len(card.Data)%RecoverBlockSize)
+		require.Len(t, card.JPEGs, 10)
+
+		files := recoverBlocks(card.Data)
+		require.Len(t, files, 10)
+		for n, jpeg := range card.JPEGs {
+			assert.Zero(t, jpeg.Offset%RecoverBlockSize)
+			assert.Equal(t, jpeg.Data, files[n], jpeg.Name)
+		}
+	}
+}
+
+func TestCheckRecovered(t *testing.T) {
+	random.Init()
+	card := GenerateForensicCard(4)
+	dir := t.TempDir()
+	for _, jpeg := range card.JPEGs {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, jpeg.Name), jpeg.Data, 0644))
+	}
+	assert.NoError(t, card.CheckRecovered(dir))
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "001.jpg")))
+	// 去掉最后一块；JPEG 可能只有一块，此时截断为空文件
+	truncated := len(card.JPEGs[2].Data) - RecoverBlockSize
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "002.jpg"), card.JPEGs[2].Data[:truncated], 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "004.jpg"), []byte("extra"), 0644))
+
+	err := card.CheckRecovered(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "001.jpg is missing")
+	assert.Contains(t, err.Error(), fmt.Sprintf("002.jpg is truncated: %d of %d", truncated, len(card.JPEGs[2].Data)))
+	assert.Contains(t, err.Error(), "004.jpg is extra")
+}
@@ -0,0 +1,254 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// FuzzRunTimeout 是单次模糊测试运行的最长时间，超过即视为卡死
+	FuzzRunTimeout = 2 * time.Second
+
+	// FuzzBudget 是一个 stage 的模糊测试总时长上限（含最小化）
+	FuzzBudget = 60 * time.Second
+
+	// fuzzMinimizeRuns 是最小化单个崩溃输入时的最大运行次数
+	fuzzMinimizeRuns = 200
+)
+
+// FuzzEnabled 判断是否开启模糊测试（BOOTLLM_FUZZ=1）
+// 默认关闭，保证与 check50 对齐的检查不受影响
+func FuzzEnabled() bool {
+	return os.Getenv("BOOTLLM_FUZZ") == "1"
+}
+
+// FuzzTimeout 在开启模糊测试时为 stage 超时追加 FuzzBudget
+func FuzzTimeout(base time.Duration) time.Duration {
+	if FuzzEnabled() {
+		return base + FuzzBudget
+	}
+	return base
+}
+
+// SanitizerFlags 是编译模糊测试二进制时使用的额外参数
+var SanitizerFlags = []string{"-g", "-fsanitize=address,undefined", "-fno-omit-frame-pointer"}
+
+// HostileNumbers 容易触发整数溢出或解析错误的数字参数
+var HostileNumbers = []string{
+	"2147483647",
+	"2147483648",
+	"-2147483648",
+	"-2147483649",
+	"4294967296",
+	"99999999999999999999999999",
+	"-1",
+	"0",
+	"+5",
+	"5x",
+	"0x10",
+	" 7",
+}
+
+// HostileTexts 返回一组不友好的文本输入（空行、超长行、非 ASCII 等）
+func HostileTexts() []string {
+	return []string{
+		"",
+		" ",
+		"\t\t\t",
+		strings.Repeat("a", 100000),
+		strings.Repeat("word ", 20000),
+		strings.Repeat("!?.", 10000),
+		"héllo wörld, ¿qué tal? ✓",
+		"\x80\x81\xfe\xff",
+		"\x7f\x01\x1b[31m",
+		"a\x00b",
+		strings.Repeat("Z", 4096) + "\n" + strings.Repeat("z", 4096),
+	}
+}
+
+// FuzzInput 描述一次模糊测试运行
+type FuzzInput struct {
+	Args []string
+	// Stdin 原样写入（不自动追加换行），写完后关闭 stdin，即程序会读到 EOF
+	Stdin string
+}
+
+// String 返回输入的可读描述（超长内容会被截断）
+func (in FuzzInput) String() string {
+	args := make([]string, len(in.Args))
+	for i, a := range in.Args {
+		args[i] = fmt.Sprintf("%q", truncate(a, 40))
+	}
+	return fmt.Sprintf("args [%s], stdin %q (%d bytes)", strings.Join(args, " "), truncate(in.Stdin, 40), len(in.Stdin))
+}
+
+// truncate 截断过长的字符串
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// FuzzFinding 描述一次失败的模糊测试运行
+type FuzzFinding struct {
+	Kind   string // crash / sanitizer / hang
+	Detail string
+	// Input 是最小化后的输入
+	Input FuzzInput
+	// Original 是最初触发问题的输入
+	Original FuzzInput
+}
+
+func (f FuzzFinding) String() string {
+	return fmt.Sprintf("%s: %s\n  input: %s", f.Kind, f.Detail, f.Input)
+}
+
+// Fuzzer 对一个已编译的 C 程序运行模糊测试
+type Fuzzer struct {
+	WorkDir string
+	Binary  string
+	// Deadline 之后不再运行新的输入
+	Deadline time.Time
+}
+
+// NewFuzzer 创建一个 Fuzzer，总时长受 FuzzBudget 限制
+func NewFuzzer(workDir, binary string) *Fuzzer {
+	return &Fuzzer{
+		WorkDir:  workDir,
+		Binary:   binary,
+		Deadline: time.Now().Add(FuzzBudget),
+	}
+}
+
+// Run 依次运行所有输入，返回发现的问题（每种问题只报告第一个，并做最小化）
+func (f *Fuzzer) Run(inputs []FuzzInput) []FuzzFinding {
+	var findings []FuzzFinding
+	seen := map[string]bool{}
+
+	for _, in := range inputs {
+		if time.Now().After(f.Deadline) {
+			break
+		}
+		kind, detail := f.runOnce(in)
+		if kind == "" || seen[kind] {
+			continue
+		}
+		seen[kind] = true
+		findings = append(findings, FuzzFinding{
+			Kind:     kind,
+			Detail:   detail,
+			Input:    f.minimize(in, kind),
+			Original: in,
+		})
+	}
+
+	return findings
+}
+
+// runOnce 运行一次并分类结果，正常结束时 kind 为空
+func (f *Fuzzer) runOnce(in FuzzInput) (kind, detail string) {
+	ctx, cancel := context.WithTimeout(context.Background(), FuzzRunTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "./"+f.Binary, in.Args...)
+	cmd.Dir = f.WorkDir
+	cmd.Stdin = strings.NewReader(in.Stdin)
+	cmd.Env = append(os.Environ(),
+		"ASAN_OPTIONS=detect_leaks=0:abort_on_error=0",
+		"UBSAN_OPTIONS=print_stacktrace=1",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	if ctx.Err() == context.DeadlineExceeded {
+		return "hang", fmt.Sprintf("did not finish within %v", FuzzRunTimeout)
+	}
+	if report := sanitizerReport(stderr.String()); report != "" {
+		return "sanitizer", report
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+			if info, ok := crashSignals[status.Signal()]; ok {
+				return "crash", fmt.Sprintf("terminated by %s (%s)", info.name, info.description)
+			}
+		}
+	}
+
+	return "", ""
+}
+
+// sanitizerReport 从 stderr 中提取 ASan/UBSan 报告的首行
+func sanitizerReport(stderr string) string {
+	for _, line := range strings.Split(stderr, "\n") {
+		if strings.Contains(line, "ERROR: AddressSanitizer") || strings.Contains(line, "runtime error:") {
+			return strings.TrimSpace(line)
+		}
+	}
+	return ""
+}
+
+// minimize 在保持同类失败的前提下，逐个缩减 stdin 和各参数
+func (f *Fuzzer) minimize(in FuzzInput, kind string) FuzzInput {
+	runs := 0
+	fails := func(candidate FuzzInput) bool {
+		if runs >= fuzzMinimizeRuns || time.Now().After(f.Deadline) {
+			return false
+		}
+		runs++
+		k, _ := f.runOnce(candidate)
+		return k == kind
+	}
+
+	best := in
+	best.Args = append([]string(nil), in.Args...)
+	best.Stdin = MinimizeString(best.Stdin, func(s string) bool {
+		c := best
+		c.Stdin = s
+		return fails(c)
+	})
+
+	for i := range best.Args {
+		best.Args[i] = MinimizeString(best.Args[i], func(s string) bool {
+			c := best
+			c.Args = append([]string(nil), best.Args...)
+			c.Args[i] = s
+			return fails(c)
+		})
+	}
+
+	return best
+}
+
+// MinimizeString 用简化版 delta debugging 缩减字符串：
+// 依次尝试删除越来越小的片段，只要 fails 仍然成立就保留删除
+func MinimizeString(s string, fails func(string) bool) string {
+	chunk := len(s) / 2
+	for chunk >= 1 {
+		removed := false
+		for start := 0; start+chunk <= len(s); {
+			candidate := s[:start] + s[start+chunk:]
+			if fails(candidate) {
+				s = candidate
+				removed = true
+				continue
+			}
+			start += chunk
+		}
+		if !removed {
+			chunk /= 2
+		}
+	}
+	return s
+}
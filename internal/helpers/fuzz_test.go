@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinimizeString(t *testing.T) {
+	input := strings.Repeat("a", 500) + "BOOM" + strings.Repeat("b", 500)
+	minimized := MinimizeString(input, func(s string) bool {
+		return strings.Contains(s, "BOOM")
+	})
+	assert.Equal(t, "BOOM", minimized)
+
+	assert.Equal(t, "", MinimizeString("anything", func(string) bool { return true }))
+	assert.Equal(t, "keep", MinimizeString("keep", func(string) bool { return false }))
+}
+
+func TestFuzzerFindsAndMinimizesCrash(t *testing.T) {
+	dir := t.TempDir()
+	// 读到包含 X 的输入时以 SIGSEGV 终止
+	script := "#!/bin/sh\ncase \"$(cat)\" in *X*) kill -SEGV $$;; esac\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prog"), []byte(script), 0755))
+
+	inputs := []FuzzInput{
+		{Stdin: "hello\n"},
+		{Stdin: strings.Repeat("y", 64) + "X" + strings.Repeat("z", 64)},
+	}
+	findings := NewFuzzer(dir, "prog").Run(inputs)
+	require.Len(t, findings, 1)
+	assert.Equal(t, "crash", findings[0].Kind)
+	assert.Contains(t, findings[0].Detail, "SIGSEGV")
+	assert.Equal(t, "X", findings[0].Input.Stdin)
+	assert.Equal(t, inputs[1], findings[0].Original)
+}
+
+func TestSanitizerReport(t *testing.T) {
+	stderr := "caesar.c:20:17: runtime error: signed integer overflow: 2147483647 + 1 cannot be represented in type 'int'\n"
+	assert.Contains(t, sanitizerReport(stderr), "signed integer overflow")
+	assert.Equal(t, "", sanitizerReport("Usage: ./caesar key\n"))
+}
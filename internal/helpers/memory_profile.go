@@ -0,0 +1,259 @@
+package helpers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MemoryProfileTimeout 是一次内存分析运行的最长时间（massif 会让程序慢 20 倍以上）
+const MemoryProfileTimeout = 45 * time.Second
+
+// MemoryProfileEnabled 判断是否开启内存分析（BOOTLLM_MEMORY_PROFILE=1）
+// massif 很慢，且结果只作参考，默认关闭
+func MemoryProfileEnabled() bool {
+	return os.Getenv("BOOTLLM_MEMORY_PROFILE") == "1"
+}
+
+// MemoryProfile 是一次运行的堆内存统计
+type MemoryProfile struct {
+	// PeakHeap 是堆上同时存活的最大字节数
+	PeakHeap int64
+	// TotalAllocated 是所有分配的字节数之和，Allocations 是分配次数；未知时为 -1
+	TotalAllocated int64
+	Allocations    int64
+	// Source 说明数据来源（"massif"、"malloc shim" 或两者）
+	Source string
+}
+
+func (p MemoryProfile) String() string {
+	s := fmt.Sprintf("peak heap %s", FormatBytes(p.PeakHeap))
+	if p.TotalAllocated >= 0 {
+		s += fmt.Sprintf(", %s allocated in %d allocations", FormatBytes(p.TotalAllocated), p.Allocations)
+	}
+	return s + " (" + p.Source + ")"
+}
+
+// FormatBytes 以 KiB / MiB 为单位显示字节数
+func FormatBytes(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+// mallocShimSource 通过 LD_PRELOAD 替换 malloc 系列函数，统计分配次数、总量与峰值，
+// 在进程退出时写入 LLM100X_MEMPROFILE 指定的文件（依赖 glibc 的 __libc_* 与 malloc_usable_size）
+const mallocShimSource = `#include <malloc.h>
+#include <stdio.h>
+#include <stdlib.h>
+
+extern void *__libc_malloc(size_t);
+extern void *__libc_calloc(size_t, size_t);
+extern void *__libc_realloc(void *, size_t);
+extern void __libc_free(void *);
+
+static size_t current, peak, total, count;
+
+static void track_alloc(void *p)
+{
+    if (p == NULL)
+        return;
+    size_t n = malloc_usable_size(p);
+    current += n;
+    total += n;
+    count++;
+    if (current > peak)
+        peak = current;
+}
+
+static void track_free(void *p)
+{
+    if (p != NULL)
+        current -= malloc_usable_size(p);
+}
+
+void *malloc(size_t n)
+{
+    void *p = __libc_malloc(n);
+    track_alloc(p);
+    return p;
+}
+
+void *calloc(size_t n, size_t size)
+{
+    void *p = __libc_calloc(n, size);
+    track_alloc(p);
+    return p;
+}
+
+void *realloc(void *old, size_t n)
+{
+    size_t old_size = old != NULL ? malloc_usable_size(old) : 0;
+    void *p = __libc_realloc(old, n);
+    if (p != NULL || n == 0)
+    {
+        current -= old_size;
+        track_alloc(p);
+    }
+    return p;
+}
+
+void free(void *p)
+{
+    track_free(p);
+    __libc_free(p);
+}
+
+__attribute__((destructor)) static void report(void)
+{
+    size_t p = peak, t = total, c = count;
+    const char *path = getenv("LLM100X_MEMPROFILE");
+    if (path == NULL)
+        return;
+    FILE *f = fopen(path, "w");
+    if (f == NULL)
+        return;
+    fprintf(f, "%zu %zu %zu\n", p, t, c);
+    fclose(f);
+}
+`
+
+// ProfileMemory 在 workDir 中运行程序并统计堆内存：
+// 有 valgrind 时用 massif 测峰值，另用 malloc shim（需要 C 编译器）统计分配总量；
+// 没有 valgrind 时峰值也取自 shim。两者都不可用时返回错误
+func ProfileMemory(workDir, program string, args ...string) (MemoryProfile, error) {
+	tmpDir, err := os.MkdirTemp("", "memprofile-")
+	if err != nil {
+		return MemoryProfile{}, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	profile := MemoryProfile{TotalAllocated: -1, Allocations: -1}
+	var sources []string
+
+	if _, err := exec.LookPath("valgrind"); err == nil {
+		peak, err := runMassif(tmpDir, workDir, program, args...)
+		if err != nil {
+			return profile, err
+		}
+		profile.PeakHeap = peak
+		sources = append(sources, "massif")
+	}
+
+	shim, shimErr := buildMallocShim(tmpDir)
+	if shimErr == nil {
+		peak, total, count, err := runWithMallocShim(shim, tmpDir, workDir, program, args...)
+		if err != nil {
+			return profile, err
+		}
+		if len(sources) == 0 {
+			profile.PeakHeap = peak
+		}
+		profile.TotalAllocated, profile.Allocations = total, count
+		sources = append(sources, "malloc shim")
+	}
+
+	if len(sources) == 0 {
+		return profile, fmt.Errorf("neither valgrind nor a C compiler for the malloc shim is available: %v", shimErr)
+	}
+	profile.Source = strings.Join(sources, " + ")
+	return profile, nil
+}
+
+// runMassif 用 valgrind massif 运行程序，返回 mem_heap_B 的最大值
+func runMassif(tmpDir, workDir, program string, args ...string) (int64, error) {
+	outFile := filepath.Join(tmpDir, "massif.out")
+	ctx, cancel := context.WithTimeout(context.Background(), MemoryProfileTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "valgrind", append([]string{"--tool=massif", "--massif-out-file=" + outFile, program}, args...)...)
+	cmd.Dir = workDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return 0, fmt.Errorf("massif failed: %v\n%s", err, string(out))
+	}
+
+	data, err := os.ReadFile(outFile)
+	if err != nil {
+		return 0, err
+	}
+	return ParseMassifPeak(string(data))
+}
+
+// ParseMassifPeak 返回 massif 输出中各快照 mem_heap_B 的最大值
+func ParseMassifPeak(output string) (int64, error) {
+	var peak int64
+	found := false
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		value, ok := strings.CutPrefix(scanner.Text(), "mem_heap_B=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid massif snapshot %q", scanner.Text())
+		}
+		found = true
+		peak = max(peak, n)
+	}
+	if !found {
+		return 0, fmt.Errorf("massif output has no snapshots")
+	}
+	return peak, nil
+}
+
+// buildMallocShim 把 mallocShimSource 编译为共享库
+func buildMallocShim(tmpDir string) (string, error) {
+	src := filepath.Join(tmpDir, "mallocshim.c")
+	if err := os.WriteFile(src, []byte(mallocShimSource), 0644); err != nil {
+		return "", err
+	}
+	lib := filepath.Join(tmpDir, "mallocshim.so")
+	for _, compiler := range []string{"clang", "cc"} {
+		if _, err := exec.LookPath(compiler); err != nil {
+			continue
+		}
+		cmd := exec.Command(compiler, "-shared", "-fPIC", "-O2", "-o", lib, src)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("failed to build malloc shim: %v\n%s", err, string(out))
+		}
+		return lib, nil
+	}
+	return "", fmt.Errorf("no C compiler found")
+}
+
+// runWithMallocShim 通过 LD_PRELOAD 加载 shim 运行程序，返回峰值、总量与分配次数
+func runWithMallocShim(shim, tmpDir, workDir, program string, args ...string) (peak, total, count int64, err error) {
+	report := filepath.Join(tmpDir, "mallocshim.out")
+	ctx, cancel := context.WithTimeout(context.Background(), MemoryProfileTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, program, args...)
+	cmd.Dir = workDir
+	cmd.Env = append(os.Environ(), "LD_PRELOAD="+shim, "LLM100X_MEMPROFILE="+report)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return 0, 0, 0, fmt.Errorf("%s failed under the malloc shim: %v\n%s", program, err, stderr.String())
+	}
+
+	data, err := os.ReadFile(report)
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("malloc shim produced no report: %v", err)
+	}
+	if _, err := fmt.Sscan(string(data), &peak, &total, &count); err != nil {
+		return 0, 0, 0, fmt.Errorf("invalid malloc shim report %q", string(data))
+	}
+	return peak, total, count, nil
+}
@@ -0,0 +1,82 @@
+package helpers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMassifPeak(t *testing.T) {
+	output := `desc: (none)
+cmd: ./speller large/dict large/text
+time_unit: i
+#-----------
+snapshot=0
+#-----------
+time=0
+mem_heap_B=0
+mem_heap_extra_B=0
+#-----------
+snapshot=1
+#-----------
+time=1000
+mem_heap_B=8013096
+mem_heap_extra_B=2289456
+#-----------
+snapshot=2
+#-----------
+time=2000
+mem_heap_B=4096
+mem_heap_extra_B=8
+`
+	peak, err := ParseMassifPeak(output)
+	require.NoError(t, err)
+	assert.Equal(t, int64(8013096), peak)
+
+	_, err = ParseMassifPeak("desc: (none)\n")
+	assert.Error(t, err)
+}
+
+func TestProfileMemoryWithMallocShim(t *testing.T) {
+	if _, err := exec.LookPath("valgrind"); err == nil {
+		t.Skip("valgrind is installed; this test covers the shim-only path")
+	}
+	if _, err := exec.LookPath("cc"); err != nil {
+		t.Skip("no C compiler available")
+	}
+
+	dir := t.TempDir()
+	src := `#include <stdlib.h>
+int main(void)
+{
+    for (int i = 0; i < 100; i++)
+    {
+        free(malloc(1000));
+    }
+    void *p = malloc(100000);
+    free(p);
+    return 0;
+}
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "prog.c"), []byte(src), 0644))
+	out, err := exec.Command("cc", "-o", filepath.Join(dir, "prog"), filepath.Join(dir, "prog.c")).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	profile, err := ProfileMemory(dir, "./prog")
+	require.NoError(t, err)
+	assert.Equal(t, "malloc shim", profile.Source)
+	assert.GreaterOrEqual(t, profile.PeakHeap, int64(100000))
+	assert.Less(t, profile.PeakHeap, int64(200000))
+	assert.GreaterOrEqual(t, profile.TotalAllocated, int64(200000))
+	assert.GreaterOrEqual(t, profile.Allocations, int64(101))
+}
+
+func TestFormatBytes(t *testing.T) {
+	assert.Equal(t, "512 B", FormatBytes(512))
+	assert.Equal(t, "1.5 KiB", FormatBytes(1536))
+	assert.Equal(t, "7.6 MiB", FormatBytes(8013096))
+}
@@ -0,0 +1,247 @@
+package helpers
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bootllm/tester-utils/runner"
+)
+
+// PythonFrame 表示 traceback 中的一个调用帧
+type PythonFrame struct {
+	File     string
+	Line     int
+	Function string // SyntaxError 等没有函数名
+	Source   string // 该行源码，可能为空
+}
+
+// PythonTraceback 表示解析后的 Python 异常信息
+type PythonTraceback struct {
+	ExceptionType string
+	Message       string
+	Frames        []PythonFrame
+	// StudentFrame 是位于学生文件中最内层的帧，找不到时为 nil
+	StudentFrame *PythonFrame
+}
+
+var (
+	pythonFrameRegex     = regexp.MustCompile(`^\s*File "(.+)", line (\d+)(?:, in (.+))?$`)
+	pythonExceptionRegex = regexp.MustCompile(`^([A-Za-z_][\w.]*(?:Error|Exception|Interrupt|Exit|Warning)|StopIteration|KeyboardInterrupt)(?::\s?(.*))?$`)
+)
+
+// ParsePythonTraceback 从程序输出中解析最后一个 traceback
+// studentFile 为学生脚本名（如 "dna.py"），用于定位最内层的学生代码帧
+// 输出中没有 traceback 时返回 nil
+func ParsePythonTraceback(output, studentFile string) *PythonTraceback {
+	output = strings.ReplaceAll(output, "\r\n", "\n")
+	lines := strings.Split(output, "\n")
+
+	// 只关心最后一个 traceback（链式异常时，最后一个才是真正终止程序的）
+	start := -1
+	for i, line := range lines {
+		if strings.HasPrefix(line, "Traceback (most recent call last):") {
+			start = i
+		}
+	}
+
+	tb := &PythonTraceback{}
+	i := start + 1
+	if start == -1 {
+		// SyntaxError / IndentationError 不一定带 "Traceback" 头
+		i = firstFrameLine(lines)
+		if i == -1 {
+			return nil
+		}
+	}
+
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if m := pythonFrameRegex.FindStringSubmatch(line); m != nil {
+			lineNo, _ := strconv.Atoi(m[2])
+			frame := PythonFrame{File: m[1], Line: lineNo, Function: m[3]}
+			// 下一行（缩进更深）是源码
+			if i+1 < len(lines) && strings.HasPrefix(lines[i+1], "    ") && !pythonFrameRegex.MatchString(lines[i+1]) {
+				frame.Source = strings.TrimSpace(lines[i+1])
+				i++
+			}
+			tb.Frames = append(tb.Frames, frame)
+			continue
+		}
+		if m := pythonExceptionRegex.FindStringSubmatch(strings.TrimSpace(line)); m != nil && !strings.HasPrefix(line, " ") {
+			tb.ExceptionType = m[1]
+			tb.Message = m[2]
+			break
+		}
+	}
+
+	if tb.ExceptionType == "" {
+		return nil
+	}
+
+	for j := len(tb.Frames) - 1; j >= 0; j-- {
+		if filepath.Base(tb.Frames[j].File) == studentFile {
+			tb.StudentFrame = &tb.Frames[j]
+			break
+		}
+	}
+
+	return tb
+}
+
+// firstFrameLine 返回第一行 `File "...", line N` 的下标
+func firstFrameLine(lines []string) int {
+	for i, line := range lines {
+		if pythonFrameRegex.MatchString(line) {
+			return i
+		}
+	}
+	return -1
+}
+
+// pythonHintRule 描述一种常见 Python 异常及对应提示
+type pythonHintRule struct {
+	exception string         // 异常类型，必须完全相同
+	message   *regexp.Regexp // 匹配异常信息，nil 表示任意
+	source    *regexp.Regexp // 匹配出错源码，nil 表示任意
+	hint      string
+}
+
+// pythonHintRules 常见错误规则表，按顺序匹配，命中第一条即停止
+var pythonHintRules = []pythonHintRule{
+	{
+		exception: "IndexError",
+		source:    regexp.MustCompile(`sys\.argv\[`),
+		hint:      "sys.argv doesn't have that many elements. Check len(sys.argv) and print a usage message before indexing into it.",
+	},
+	{
+		exception: "ValueError",
+		message:   regexp.MustCompile(`invalid literal for int\(\)|could not convert string to float`),
+		hint:      "The input couldn't be converted to a number. Wrap int(input()) / float(input()) in a try/except ValueError and prompt again.",
+	},
+	{
+		exception: "ModuleNotFoundError",
+		message:   regexp.MustCompile(`No module named 'cs50'`),
+		hint:      "The cs50 library isn't available here. Use Python's built-in input() with int()/float() instead of get_int/get_float.",
+	},
+	{
+		exception: "ModuleNotFoundError",
+		hint:      "You imported a module that isn't installed. Stick to Python's standard library (csv, sys, re, ...) for this problem.",
+	},
+	{
+		exception: "FileNotFoundError",
+		hint:      "Your program tried to open a file that doesn't exist. Open the path given on the command line (sys.argv) rather than a hardcoded one.",
+	},
+	{
+		exception: "IndexError",
+		hint:      "You indexed past the end of a list or string. Check your loop bounds (range(len(x)) already stops at len(x) - 1).",
+	},
+	{
+		exception: "KeyError",
+		hint:      "You looked up a dictionary key that doesn't exist. Check the key's spelling and case (CSV headers are case-sensitive).",
+	},
+	{
+		exception: "TypeError",
+		message:   regexp.MustCompile(`can only concatenate str|unsupported operand type|'<' not supported`),
+		hint:      "You mixed strings and numbers. Convert with int() or str() before combining or comparing them.",
+	},
+	{
+		exception: "NameError",
+		hint:      "You used a name that was never defined. Check its spelling and that it's assigned (or imported) before this line.",
+	},
+	{
+		exception: "IndentationError",
+		hint:      "Python uses indentation to group code. Make sure each block is indented consistently (4 spaces, no mixed tabs).",
+	},
+	{
+		exception: "SyntaxError",
+		hint:      "Python couldn't parse this line. Look for a missing colon, parenthesis or quote on this line or the one above.",
+	},
+}
+
+// Hint 返回针对该异常的提示，没有匹配规则时返回空字符串
+func (tb *PythonTraceback) Hint() string {
+	source := ""
+	if tb.StudentFrame != nil {
+		source = tb.StudentFrame.Source
+	}
+	for _, rule := range pythonHintRules {
+		if rule.exception != tb.ExceptionType {
+			continue
+		}
+		if rule.message != nil && !rule.message.MatchString(tb.Message) {
+			continue
+		}
+		if rule.source != nil && !rule.source.MatchString(source) {
+			continue
+		}
+		return rule.hint
+	}
+	return ""
+}
+
+// String 将 traceback 格式化为简洁的说明
+func (tb *PythonTraceback) String() string {
+	var b strings.Builder
+
+	b.WriteString(tb.ExceptionType)
+	if tb.Message != "" {
+		fmt.Fprintf(&b, ": %s", tb.Message)
+	}
+	b.WriteString("\n")
+
+	if f := tb.StudentFrame; f != nil {
+		fmt.Fprintf(&b, "  File \"%s\", line %d", filepath.Base(f.File), f.Line)
+		if f.Function != "" {
+			fmt.Fprintf(&b, ", in %s", f.Function)
+		}
+		b.WriteString("\n")
+		if f.Source != "" {
+			fmt.Fprintf(&b, "    %s\n", f.Source)
+		}
+	}
+
+	if hint := tb.Hint(); hint != "" {
+		fmt.Fprintf(&b, "  Hint: %s\n", hint)
+	}
+
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// PythonCrashError 表示 Python 程序因未捕获的异常而终止
+// 异常信息与输出比对结果分开展示
+type PythonCrashError struct {
+	Script    string
+	Traceback *PythonTraceback
+	Err       error // 原始的输出/退出码比对错误
+}
+
+func (e *PythonCrashError) Error() string {
+	return fmt.Sprintf("%s crashed with %s\nOutput check: %v", e.Script, e.Traceback, e.Err)
+}
+
+func (e *PythonCrashError) Unwrap() error {
+	return e.Err
+}
+
+// ExplainPythonError 检查运行结果中是否有 traceback
+// 有则返回 *PythonCrashError，否则原样返回 err
+func ExplainPythonError(r *runner.Runner, script string, err error) error {
+	if err == nil || r.Result() == nil {
+		return err
+	}
+
+	result := r.Result()
+	// PTY 模式下 stderr 会混入 stdout，因此两者都要检查
+	tb := ParsePythonTraceback(string(result.Stderr), script)
+	if tb == nil {
+		tb = ParsePythonTraceback(string(result.Stdout), script)
+	}
+	if tb == nil {
+		return err
+	}
+
+	return &PythonCrashError{Script: script, Traceback: tb, Err: err}
+}
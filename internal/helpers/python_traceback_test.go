@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePythonTraceback(t *testing.T) {
+	stderr := `Traceback (most recent call last):
+  File "/workspace/dna/dna.py", line 40, in <module>
+    main()
+  File "/workspace/dna/dna.py", line 7, in main
+    database = sys.argv[1]
+IndexError: list index out of range
+`
+	tb := ParsePythonTraceback(stderr, "dna.py")
+	require.NotNil(t, tb)
+	assert.Equal(t, "IndexError", tb.ExceptionType)
+	assert.Equal(t, "list index out of range", tb.Message)
+	require.Len(t, tb.Frames, 2)
+	require.NotNil(t, tb.StudentFrame)
+	assert.Equal(t, 7, tb.StudentFrame.Line)
+	assert.Equal(t, "main", tb.StudentFrame.Function)
+	assert.Equal(t, "database = sys.argv[1]", tb.StudentFrame.Source)
+	assert.Contains(t, tb.Hint(), "len(sys.argv)")
+}
+
+func TestParsePythonTracebackInnermostStudentFrame(t *testing.T) {
+	stderr := `Traceback (most recent call last):
+  File "cash.py", line 12, in <module>
+    main()
+  File "cash.py", line 4, in main
+    dollars = int(input("Change owed: "))
+              ^^^^^^^^^^^^^^^^^^^^^^^^^^^
+ValueError: invalid literal for int() with base 10: 'foo'
+`
+	tb := ParsePythonTraceback(stderr, "cash.py")
+	require.NotNil(t, tb)
+	assert.Equal(t, "ValueError", tb.ExceptionType)
+	assert.Equal(t, 4, tb.StudentFrame.Line)
+	assert.Contains(t, tb.Hint(), "try/except ValueError")
+	assert.Contains(t, tb.String(), "File \"cash.py\", line 4, in main")
+}
+
+func TestParsePythonTracebackModuleNotFound(t *testing.T) {
+	stderr := `Traceback (most recent call last):
+  File "/tmp/hello.py", line 1, in <module>
+    from cs50 import get_string
+ModuleNotFoundError: No module named 'cs50'
+`
+	tb := ParsePythonTraceback(stderr, "hello.py")
+	require.NotNil(t, tb)
+	assert.Equal(t, "ModuleNotFoundError", tb.ExceptionType)
+	assert.Contains(t, tb.Hint(), "cs50 library")
+}
+
+func TestParsePythonTracebackSyntaxError(t *testing.T) {
+	stderr := `  File "/workspace/mario.py", line 3
+    print("#"
+         ^
+SyntaxError: '(' was never closed
+`
+	tb := ParsePythonTraceback(stderr, "mario.py")
+	require.NotNil(t, tb)
+	assert.Equal(t, "SyntaxError", tb.ExceptionType)
+	assert.Equal(t, 3, tb.StudentFrame.Line)
+	assert.Equal(t, "", tb.StudentFrame.Function)
+	assert.Equal(t, `print("#"`, tb.StudentFrame.Source)
+}
+
+func TestParsePythonTracebackNone(t *testing.T) {
+	assert.Nil(t, ParsePythonTraceback("Grade 7\n", "readability.py"))
+	assert.Nil(t, ParsePythonTraceback("", "readability.py"))
+}
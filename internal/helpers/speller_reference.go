@@ -0,0 +1,182 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SpellerMaxLength 是 speller 中 LENGTH 的值，更长的单词会被跳过
+const SpellerMaxLength = 45
+
+// SpellerResult 是一次拼写检查的结果
+type SpellerResult struct {
+	// Misspelled 按在文本中出现的顺序排列，保留原始大小写
+	Misspelled []string
+	Dictionary int
+	Text       int
+}
+
+// ReferenceSpeller 按 speller.c 的分词规则检查 text：
+//   - 单词由字母和撇号组成，撇号不能出现在开头
+//   - 超过 SpellerMaxLength 个字符的字母串整体跳过
+//   - 含数字的字母数字串整体跳过
+//   - 文件末尾没有分隔符的单词不计入（与 speller.c 的读取循环一致）
+//
+// 字典中每个空白分隔的词计为一个单词，比较时不区分大小写
+func ReferenceSpeller(dict, text string) SpellerResult {
+	words := strings.Fields(dict)
+	known := make(map[string]bool, len(words))
+	for _, w := range words {
+		known[strings.ToLower(w)] = true
+	}
+
+	result := SpellerResult{Misspelled: []string{}, Dictionary: len(words)}
+	var word []byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case isASCIIAlpha(c) || (c == '\'' && len(word) > 0):
+			word = append(word, c)
+			if len(word) > SpellerMaxLength {
+				// 跳过剩余字母，以及紧随其后的一个字符
+				for i++; i < len(text) && isASCIIAlpha(text[i]); i++ {
+				}
+				word = word[:0]
+			}
+		case c >= '0' && c <= '9':
+			// 跳过剩余的字母数字，以及紧随其后的一个字符
+			for i++; i < len(text) && (isASCIIAlpha(text[i]) || (text[i] >= '0' && text[i] <= '9')); i++ {
+			}
+			word = word[:0]
+		case len(word) > 0:
+			result.Text++
+			if !known[strings.ToLower(string(word))] {
+				result.Misspelled = append(result.Misspelled, string(word))
+			}
+			word = word[:0]
+		}
+	}
+	return result
+}
+
+func isASCIIAlpha(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// spellerCounterRegex 匹配 "WORDS MISSPELLED:     955" 等计数行
+var spellerCounterRegex = regexp.MustCompile(`(?m)^WORDS (MISSPELLED|IN DICTIONARY|IN TEXT):\s*(\d+)`)
+
+// ParseSpellerOutput 解析 speller 的完整输出（拼错单词列表与三个计数）
+func ParseSpellerOutput(output string) (SpellerResult, error) {
+	result := SpellerResult{Misspelled: []string{}}
+
+	inMisspelled := false
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line == "MISSPELLED WORDS" {
+			inMisspelled = true
+			continue
+		}
+		if strings.HasPrefix(line, "WORDS MISSPELLED:") {
+			break
+		}
+		if inMisspelled && line != "" {
+			result.Misspelled = append(result.Misspelled, line)
+		}
+	}
+	if !inMisspelled {
+		return result, fmt.Errorf("output is missing the \"MISSPELLED WORDS\" header")
+	}
+
+	counters := map[string]int{}
+	for _, m := range spellerCounterRegex.FindAllStringSubmatch(output, -1) {
+		n, _ := strconv.Atoi(m[2])
+		counters[m[1]] = n
+	}
+	for _, name := range []string{"MISSPELLED", "IN DICTIONARY", "IN TEXT"} {
+		if _, ok := counters[name]; !ok {
+			return result, fmt.Errorf("output is missing \"WORDS %s\"", name)
+		}
+	}
+	if counters["MISSPELLED"] != len(result.Misspelled) {
+		return result, fmt.Errorf("WORDS MISSPELLED is %d but %d words were listed", counters["MISSPELLED"], len(result.Misspelled))
+	}
+	result.Dictionary = counters["IN DICTIONARY"]
+	result.Text = counters["IN TEXT"]
+	return result, nil
+}
+
+// CompareSpellerResults 比较学生结果与参考结果，列出漏报和误报的单词以及不一致的计数
+func CompareSpellerResults(expected, actual SpellerResult) error {
+	var problems []string
+
+	missing, extra := multisetDiff(expected.Misspelled, actual.Misspelled)
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing misspelled words: %s", summarizeWords(missing)))
+	}
+	if len(extra) > 0 {
+		problems = append(problems, fmt.Sprintf("words wrongly reported as misspelled: %s", summarizeWords(extra)))
+	}
+	if len(missing) == 0 && len(extra) == 0 {
+		for i := range expected.Misspelled {
+			if expected.Misspelled[i] != actual.Misspelled[i] {
+				problems = append(problems, fmt.Sprintf("misspelled words are out of order: expected %q at position %d, got %q",
+					expected.Misspelled[i], i+1, actual.Misspelled[i]))
+				break
+			}
+		}
+	}
+
+	if expected.Dictionary != actual.Dictionary {
+		problems = append(problems, fmt.Sprintf("WORDS IN DICTIONARY: expected %d, got %d", expected.Dictionary, actual.Dictionary))
+	}
+	if expected.Text != actual.Text {
+		problems = append(problems, fmt.Sprintf("WORDS IN TEXT: expected %d, got %d", expected.Text, actual.Text))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
+// multisetDiff 返回 expected 中多出的元素与 actual 中多出的元素（按出现顺序）
+func multisetDiff(expected, actual []string) (missing, extra []string) {
+	counts := map[string]int{}
+	for _, w := range actual {
+		counts[w]++
+	}
+	for _, w := range expected {
+		if counts[w] > 0 {
+			counts[w]--
+		} else {
+			missing = append(missing, w)
+		}
+	}
+	for _, w := range actual {
+		if counts[w] > 0 {
+			counts[w]--
+			extra = append(extra, w)
+		}
+	}
+	return missing, extra
+}
+
+// summarizeWords 最多列出 10 个单词
+func summarizeWords(words []string) string {
+	const limit = 10
+	quoted := make([]string, 0, limit)
+	for i, w := range words {
+		if i == limit {
+			break
+		}
+		quoted = append(quoted, strconv.Quote(w))
+	}
+	s := strings.Join(quoted, ", ")
+	if len(words) > limit {
+		s += fmt.Sprintf(" (and %d more)", len(words)-limit)
+	}
+	return s
+}
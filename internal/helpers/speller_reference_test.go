@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReferenceSpeller(t *testing.T) {
+	dict := "cat\ncat's\ndog\n"
+	long := strings.Repeat("a", SpellerMaxLength+1)
+	maxLen := strings.Repeat("b", SpellerMaxLength)
+	text := "The Cat's dog, 'cat cats abc123 x1y " + long + " " + maxLen + " DOG.\ntrailing"
+
+	result := ReferenceSpeller(dict, text)
+	assert.Equal(t, []string{"The", "cats", maxLen}, result.Misspelled)
+	assert.Equal(t, 3, result.Dictionary)
+	// The, Cat's, dog, cat, cats, maxLen, DOG；末尾的 trailing 没有分隔符，不计入
+	assert.Equal(t, 7, result.Text)
+}
+
+func TestParseSpellerOutput(t *testing.T) {
+	output := "\nMISSPELLED WORDS\n\nThe\ncats\n\nWORDS MISSPELLED:     2\nWORDS IN DICTIONARY:  3\nWORDS IN TEXT:        7\nTIME IN load:         0.00\n"
+	result, err := ParseSpellerOutput(output)
+	require.NoError(t, err)
+	assert.Equal(t, SpellerResult{Misspelled: []string{"The", "cats"}, Dictionary: 3, Text: 7}, result)
+
+	_, err = ParseSpellerOutput("MISSPELLED WORDS\n\nThe\n\nWORDS MISSPELLED: 2\nWORDS IN DICTIONARY: 3\nWORDS IN TEXT: 7\n")
+	assert.ErrorContains(t, err, "WORDS MISSPELLED is 2 but 1 words were listed")
+}
+
+func TestCompareSpellerResults(t *testing.T) {
+	expected := SpellerResult{Misspelled: []string{"a", "b", "b"}, Dictionary: 10, Text: 20}
+	assert.NoError(t, CompareSpellerResults(expected, expected))
+
+	err := CompareSpellerResults(expected, SpellerResult{Misspelled: []string{"b", "c"}, Dictionary: 10, Text: 19})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `missing misspelled words: "a", "b"`)
+	assert.Contains(t, err.Error(), `wrongly reported as misspelled: "c"`)
+	assert.Contains(t, err.Error(), "WORDS IN TEXT: expected 20, got 19")
+
+	err = CompareSpellerResults(expected, SpellerResult{Misspelled: []string{"b", "a", "b"}, Dictionary: 10, Text: 20})
+	assert.ErrorContains(t, err, "out of order")
+}
@@ -0,0 +1,307 @@
+package helpers
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// SQLTokenKind classifies a token produced by TokenizeSQL
+type SQLTokenKind int
+
+const (
+	// SQLWord is a keyword or bare identifier
+	SQLWord SQLTokenKind = iota
+	// SQLNumber is a numeric literal
+	SQLNumber
+	// SQLString is a '...' string literal
+	SQLString
+	// SQLQuotedIdentifier is a "...", `...` or [...] identifier
+	SQLQuotedIdentifier
+	// SQLSymbol is an operator or punctuation
+	SQLSymbol
+)
+
+// SQLToken is one lexical token of a query
+type SQLToken struct {
+	Kind SQLTokenKind
+	// Text is the token as written, without quotes for strings and quoted identifiers
+	Text string
+}
+
+// sqlOperators are the multi-character operators, longest first
+var sqlOperators = []string{"<=", ">=", "<>", "!=", "==", "||"}
+
+// TokenizeSQL splits a query into tokens, skipping whitespace and comments
+func TokenizeSQL(query string) []SQLToken {
+	var tokens []SQLToken
+	isWord := func(c byte) bool {
+		return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c >= 0x80
+	}
+	isDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case strings.HasPrefix(query[i:], "--"):
+			for i < len(query) && query[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "/*"):
+			if end := strings.Index(query[i+2:], "*/"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			closing, kind := c, SQLQuotedIdentifier
+			if c == '[' {
+				closing = ']'
+			}
+			if c == '\'' {
+				kind = SQLString
+			}
+			var text strings.Builder
+			for i++; i < len(query); i++ {
+				if query[i] == closing {
+					if closing != ']' && i+1 < len(query) && query[i+1] == closing {
+						text.WriteByte(closing)
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				text.WriteByte(query[i])
+			}
+			tokens = append(tokens, SQLToken{kind, text.String()})
+		case isDigit(c) || c == '.' && i+1 < len(query) && isDigit(query[i+1]):
+			start := i
+			for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+				i++
+			}
+			if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+				i++
+				if i < len(query) && (query[i] == '+' || query[i] == '-') {
+					i++
+				}
+				for i < len(query) && isDigit(query[i]) {
+					i++
+				}
+			}
+			tokens = append(tokens, SQLToken{SQLNumber, query[start:i]})
+		case isWord(c):
+			start := i
+			for i < len(query) && isWord(query[i]) {
+				i++
+			}
+			tokens = append(tokens, SQLToken{SQLWord, query[start:i]})
+		default:
+			text := query[i : i+1]
+			for _, op := range sqlOperators {
+				if strings.HasPrefix(query[i:], op) {
+					text = op
+					break
+				}
+			}
+			tokens = append(tokens, SQLToken{SQLSymbol, text})
+			i += len(text)
+		}
+	}
+	return tokens
+}
+
+// QueryAnalysis is what is known about one executed student query
+type QueryAnalysis struct {
+	Query  string
+	Tokens []SQLToken
+	// Plan holds the detail column of EXPLAIN QUERY PLAN, e.g. "SCAN songs"
+	Plan     []string
+	Duration time.Duration
+	Rows     [][]any
+}
+
+// AnalyzeSQLQuery records the query plan, then runs the query and times it
+func AnalyzeSQLQuery(db *sql.DB, query string) (*QueryAnalysis, error) {
+	plan, err := QueryRows(db, "EXPLAIN QUERY PLAN "+query)
+	if err != nil {
+		return nil, err
+	}
+	a := &QueryAnalysis{Query: query, Tokens: TokenizeSQL(query)}
+	for _, row := range plan {
+		a.Plan = append(a.Plan, fmt.Sprint(row[len(row)-1]))
+	}
+
+	start := time.Now()
+	a.Rows, err = QueryRows(db, query)
+	a.Duration = time.Since(start)
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// SQLRuleSeverity decides whether a violated rule fails the check
+type SQLRuleSeverity int
+
+const (
+	// SQLWarning only reports the violation
+	SQLWarning SQLRuleSeverity = iota
+	// SQLFailure fails the check
+	SQLFailure
+)
+
+// SQLRule is a structural requirement on a query
+type SQLRule struct {
+	Severity SQLRuleSeverity
+	// check returns a description of the violation, or "" when the rule holds
+	check func(a *QueryAnalysis) string
+}
+
+// Check evaluates rules, returning the violations grouped by severity
+func (a *QueryAnalysis) Check(rules []SQLRule) (failures, warnings []string) {
+	for _, rule := range rules {
+		violation := rule.check(a)
+		if violation == "" {
+			continue
+		}
+		if rule.Severity == SQLFailure {
+			failures = append(failures, violation)
+		} else {
+			warnings = append(warnings, violation)
+		}
+	}
+	return failures, warnings
+}
+
+// upper returns the upper-cased text of a word token, or "" for other tokens
+func (t SQLToken) upper() string {
+	if t.Kind != SQLWord {
+		return ""
+	}
+	return strings.ToUpper(t.Text)
+}
+
+// NoSelectStar forbids SELECT * (COUNT(*) is fine)
+func NoSelectStar(severity SQLRuleSeverity) SQLRule {
+	return SQLRule{Severity: severity, check: func(a *QueryAnalysis) string {
+		for i := 1; i < len(a.Tokens); i++ {
+			if a.Tokens[i].Kind != SQLSymbol || a.Tokens[i].Text != "*" {
+				continue
+			}
+			prev := a.Tokens[i-1]
+			if prev.upper() == "SELECT" || prev.upper() == "DISTINCT" || prev.Text == "," || prev.Text == "." {
+				return "uses SELECT *; select only the columns the problem asks for"
+			}
+		}
+		return ""
+	}}
+}
+
+// isIDColumn reports whether a token names an id column (id, movie_id, ...)
+func isIDColumn(t SQLToken) bool {
+	if t.Kind != SQLWord && t.Kind != SQLQuotedIdentifier {
+		return false
+	}
+	name := strings.ToLower(t.Text)
+	return name == "id" || strings.HasSuffix(name, "_id")
+}
+
+// isComparison reports whether a token compares values
+func isComparison(t SQLToken) bool {
+	switch t.Text {
+	case "=", "==", "!=", "<>":
+		return t.Kind == SQLSymbol
+	}
+	return t.upper() == "IN"
+}
+
+// NoHardcodedIDs forbids comparing an id column with a numeric literal,
+// such as artist_id = 54 or id IN (1, 2); look the id up by name instead
+func NoHardcodedIDs(severity SQLRuleSeverity) SQLRule {
+	return SQLRule{Severity: severity, check: func(a *QueryAnalysis) string {
+		tokens := a.Tokens
+		for i := 0; i+1 < len(tokens); i++ {
+			if !isComparison(tokens[i]) {
+				continue
+			}
+			// id = 54, id IN (1, 2)
+			if i > 0 && isIDColumn(tokens[i-1]) {
+				j := i + 1
+				if j < len(tokens) && tokens[j].Text == "(" {
+					j++
+				}
+				if j < len(tokens) && tokens[j].Kind == SQLNumber {
+					return fmt.Sprintf("hardcodes an id (%s %s %s); look it up with a subquery or JOIN instead",
+						tokens[i-1].Text, tokens[i].Text, tokens[j].Text)
+				}
+			}
+			// 54 = id, 54 = songs.artist_id
+			j := i + 1
+			if j+2 < len(tokens) && tokens[j+1].Text == "." {
+				j += 2
+			}
+			if i > 0 && tokens[i-1].Kind == SQLNumber && isIDColumn(tokens[j]) {
+				return fmt.Sprintf("hardcodes an id (%s %s %s); look it up with a subquery or JOIN instead",
+					tokens[i-1].Text, tokens[i].Text, tokens[j].Text)
+			}
+		}
+		return ""
+	}}
+}
+
+// RequireJoinOrSubquery requires a JOIN or a nested SELECT
+func RequireJoinOrSubquery(severity SQLRuleSeverity) SQLRule {
+	return SQLRule{Severity: severity, check: func(a *QueryAnalysis) string {
+		selects := 0
+		for _, t := range a.Tokens {
+			switch t.upper() {
+			case "JOIN":
+				return ""
+			case "SELECT":
+				selects++
+			}
+		}
+		if selects > 1 {
+			return ""
+		}
+		return "uses neither a JOIN nor a subquery; the answer should be derived from the related table"
+	}}
+}
+
+// planScan matches a full table scan in EXPLAIN QUERY PLAN output
+var planScan = regexp.MustCompile(`^SCAN (?:TABLE )?([A-Za-z_]\w*)`)
+
+// MaxTableScans limits how many times the plan fully scans any one table
+// (scans that use an index are not counted)
+func MaxTableScans(n int, severity SQLRuleSeverity) SQLRule {
+	return SQLRule{Severity: severity, check: func(a *QueryAnalysis) string {
+		scans := map[string]int{}
+		for _, detail := range a.Plan {
+			m := planScan.FindStringSubmatch(detail)
+			if m == nil || m[1] == "CONSTANT" || strings.Contains(detail, "INDEX") {
+				continue
+			}
+			scans[m[1]]++
+			if scans[m[1]] > n {
+				return fmt.Sprintf("scans the whole %s table %d times; at most %d expected (query plan: %s)",
+					m[1], scans[m[1]], n, strings.Join(a.Plan, "; "))
+			}
+		}
+		return ""
+	}}
+}
+
+// MaxQueryDuration limits how long the query may take
+func MaxQueryDuration(limit time.Duration, severity SQLRuleSeverity) SQLRule {
+	return SQLRule{Severity: severity, check: func(a *QueryAnalysis) string {
+		if a.Duration > limit {
+			return fmt.Sprintf("took %v, more than %v", a.Duration.Round(time.Millisecond), limit)
+		}
+		return ""
+	}}
+}
@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenizeSQL(t *testing.T) {
+	tokens := TokenizeSQL("SELECT \"name\", COUNT(*) -- comment\nFROM songs /* x */ WHERE name != 'it''s' AND energy >= .5e1;")
+	var texts []string
+	var kinds []SQLTokenKind
+	for _, tok := range tokens {
+		texts = append(texts, tok.Text)
+		kinds = append(kinds, tok.Kind)
+	}
+	assert.Equal(t, []string{"SELECT", "name", ",", "COUNT", "(", "*", ")", "FROM", "songs", "WHERE", "name", "!=", "it's", "AND", "energy", ">=", ".5e1", ";"}, texts)
+	assert.Equal(t, SQLQuotedIdentifier, kinds[1])
+	assert.Equal(t, SQLString, kinds[12])
+	assert.Equal(t, SQLNumber, kinds[16])
+}
+
+func violations(query string, rule SQLRule) []string {
+	a := &QueryAnalysis{Query: query, Tokens: TokenizeSQL(query)}
+	failures, warnings := a.Check([]SQLRule{rule})
+	return append(failures, warnings...)
+}
+
+func TestSQLRules(t *testing.T) {
+	star := NoSelectStar(SQLFailure)
+	assert.NotEmpty(t, violations("SELECT * FROM songs", star))
+	assert.NotEmpty(t, violations("SELECT name FROM (SELECT s.* FROM songs s)", star))
+	assert.Empty(t, violations("SELECT COUNT(*) FROM songs", star))
+	assert.Empty(t, violations("SELECT energy * 2 FROM songs", star))
+
+	ids := NoHardcodedIDs(SQLFailure)
+	assert.Equal(t, []string{"hardcodes an id (artist_id = 54); look it up with a subquery or JOIN instead"},
+		violations("SELECT name FROM songs WHERE artist_id = 54", ids))
+	assert.NotEmpty(t, violations("SELECT name FROM people WHERE id IN (102, 129)", ids))
+	assert.NotEmpty(t, violations("SELECT name FROM songs WHERE 54 = songs.artist_id", ids))
+	assert.Empty(t, violations("SELECT name FROM songs WHERE artist_id = (SELECT id FROM artists WHERE name = 'Drake')", ids))
+	assert.Empty(t, violations("SELECT birth FROM people WHERE birth = 1958", ids))
+
+	join := RequireJoinOrSubquery(SQLFailure)
+	assert.NotEmpty(t, violations("SELECT name FROM songs WHERE artist_id = 54", join))
+	assert.Empty(t, violations("SELECT songs.name FROM songs JOIN artists ON artists.id = songs.artist_id", join))
+	assert.Empty(t, violations("SELECT name FROM songs WHERE artist_id IN (SELECT id FROM artists)", join))
+}
+
+func TestAnalyzeSQLQuery(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE songs (id INTEGER, name TEXT, artist_id INTEGER);
+		CREATE TABLE artists (id INTEGER, name TEXT);
+		INSERT INTO artists VALUES (1, 'Drake');
+		INSERT INTO songs VALUES (1, 'One Dance', 1), (2, 'Other', 2);`)
+	require.NoError(t, err)
+
+	a, err := AnalyzeSQLQuery(db, "SELECT name FROM songs WHERE artist_id = (SELECT id FROM artists WHERE name = 'Drake');")
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{"One Dance"}}, a.Rows)
+	assert.NotEmpty(t, a.Plan)
+
+	failures, warnings := a.Check([]SQLRule{MaxTableScans(1, SQLFailure), MaxQueryDuration(time.Second, SQLWarning)})
+	assert.Empty(t, failures)
+	assert.Empty(t, warnings)
+
+	// songs 被扫描两次
+	a, err = AnalyzeSQLQuery(db, "SELECT name FROM songs WHERE artist_id IN (SELECT artist_id FROM songs WHERE name = 'Other') AND name != 'x'")
+	require.NoError(t, err)
+	failures, _ = a.Check([]SQLRule{MaxTableScans(1, SQLFailure)})
+	require.Len(t, failures, 1)
+	assert.Contains(t, failures[0], "scans the whole songs table 2 times")
+
+	a.Duration = 2 * time.Second
+	_, warnings = a.Check([]SQLRule{MaxQueryDuration(time.Second, SQLWarning)})
+	assert.Equal(t, []string{"took 2s, more than 1s"}, warnings)
+}
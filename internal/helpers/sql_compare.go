@@ -0,0 +1,404 @@
+package helpers
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ColumnOrder controls how actual columns are matched against expected columns
+type ColumnOrder int
+
+const (
+	// ExactColumns requires columns in the same order as the expected rows
+	ExactColumns ColumnOrder = iota
+	// AnyColumnOrder accepts any permutation of the columns, as long as the
+	// same permutation is used for every row
+	AnyColumnOrder
+)
+
+// maxPermutedColumns caps the number of columns AnyColumnOrder will permute
+const maxPermutedColumns = 6
+
+// ResultSpec describes how a query result is compared with the expected rows
+type ResultSpec struct {
+	// Ordered requires rows to appear in the expected order
+	Ordered bool
+	// Ties optionally assigns each expected row an ORDER BY key group; rows that
+	// share a group may appear in any order among themselves. nil means strict order.
+	Ties []int
+	// Columns is the column-order policy
+	Columns ColumnOrder
+	// Tolerance maps an expected column index to the allowed absolute difference
+	// for numeric values in that column
+	Tolerance map[int]float64
+	// NullMatchesEmpty lets NULL and the empty string match each other
+	NullMatchesEmpty bool
+}
+
+// MaxDiffRows is the number of missing or unexpected rows shown in a diff
+const MaxDiffRows = 5
+
+// ResultDiff is the structured difference between expected and actual rows
+type ResultDiff struct {
+	ExpectedRows, ActualRows int
+	// ExpectedColumns and ActualColumns are set when the column counts differ
+	ExpectedColumns, ActualColumns int
+	// Missing are expected rows that are absent from the result
+	Missing [][]any
+	// Unexpected are result rows that are not expected
+	Unexpected [][]any
+	// OrderMismatch is the first position whose row is out of order, or -1
+	OrderMismatch int
+	// ExpectedAt and ActualAt are the rows at OrderMismatch
+	ExpectedAt, ActualAt []any
+	// Permutation maps expected column i to actual column Permutation[i]
+	Permutation []int
+	// Tolerance is copied from the ResultSpec to describe float windows
+	Tolerance map[int]float64
+}
+
+// Error describes the difference row by row: at most MaxDiffRows missing and
+// unexpected rows, the first position where the order diverges, and for
+// columns with a tolerance the student's value next to the accepted window
+func (d *ResultDiff) Error() string {
+	switch {
+	case d.ExpectedColumns != d.ActualColumns:
+		return fmt.Sprintf("expected %d columns, got %d columns", d.ExpectedColumns, d.ActualColumns)
+	case len(d.Missing) > 0 || len(d.Unexpected) > 0:
+		var b strings.Builder
+		fmt.Fprintf(&b, "result mismatch: expected %d rows, got %d rows", d.ExpectedRows, d.ActualRows)
+		if window := d.toleranceWindows(); window != "" {
+			b.WriteString("\n" + window)
+		}
+		writeDiffRows(&b, "missing", d.Missing)
+		writeDiffRows(&b, "unexpected", d.Unexpected)
+		return b.String()
+	default:
+		return fmt.Sprintf("result mismatch: rows are in the wrong order, first difference at row %d: expected %s, got %s",
+			d.OrderMismatch+1, FormatRow(d.ExpectedAt), FormatRow(d.ActualAt))
+	}
+}
+
+// writeDiffRows lists at most MaxDiffRows rows under a heading
+func writeDiffRows(b *strings.Builder, label string, rows [][]any) {
+	if len(rows) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n%d %s row(s):", len(rows), label)
+	for i, row := range rows {
+		if i == MaxDiffRows {
+			fmt.Fprintf(b, "\n  ... and %d more", len(rows)-MaxDiffRows)
+			break
+		}
+		b.WriteString("\n  " + FormatRow(row))
+	}
+}
+
+// toleranceWindows explains a single mismatched row with toleranced columns,
+// e.g. "column 1: got 0.67, expected 0.65906 ± 0.01 (0.64906 to 0.66906)"
+func (d *ResultDiff) toleranceWindows() string {
+	if len(d.Tolerance) == 0 || len(d.Missing) != 1 || len(d.Unexpected) != 1 {
+		return ""
+	}
+	expected, actual := d.Missing[0], d.Unexpected[0]
+
+	var lines []string
+	for c := range expected {
+		tolerance, ok := d.Tolerance[c]
+		e, eNumeric := numericValue(expected[c])
+		if !ok || !eNumeric {
+			continue
+		}
+		got := actual[d.Permutation[c]]
+		if a, aNumeric := numericValue(got); aNumeric && math.Abs(a-e) <= tolerance {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("column %d: got %s, expected %.6g ± %.6g (%.6g to %.6g)",
+			c+1, formatValue(got), e, tolerance, e-tolerance, e+tolerance))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// SingleColumn turns a list of values into one-column rows
+func SingleColumn[T any](values []T) [][]any {
+	rows := make([][]any, len(values))
+	for i, v := range values {
+		rows[i] = []any{v}
+	}
+	return rows
+}
+
+// QueryRows executes a query and returns every row as typed values
+// (int64, float64, string or nil). The query is interrupted after
+// SQLQueryTimeout and fails if it returns more than MaxResultRows rows.
+func QueryRows(db *sql.DB, query string) ([][]any, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), SQLQueryTimeout)
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, queryError(ctx, err)
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, fmt.Errorf("query error: %v", err)
+	}
+
+	var results [][]any
+	for rows.Next() {
+		if len(results) == MaxResultRows {
+			return nil, fmt.Errorf("query returned more than %d rows", MaxResultRows)
+		}
+		row := make([]any, len(columns))
+		ptrs := make([]any, len(columns))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, fmt.Errorf("scan error: %v", err)
+		}
+		for i, v := range row {
+			if b, ok := v.([]byte); ok {
+				row[i] = string(b)
+			}
+		}
+		results = append(results, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, queryError(ctx, err)
+	}
+	return results, nil
+}
+
+// queryError reports a timeout distinctly from other query errors
+func queryError(ctx context.Context, err error) error {
+	if ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("query did not finish within %v", SQLQueryTimeout)
+	}
+	return fmt.Errorf("query error: %v", err)
+}
+
+// TestSQLResult runs the query in filename and compares it with expected rows
+func TestSQLResult(db *sql.DB, workDir, filename string, expected [][]any, spec ResultSpec) error {
+	query, err := ReadSQLFile(workDir, filename)
+	if err != nil {
+		return err
+	}
+	if err := ValidateSQLQuery(query); err != nil {
+		return err
+	}
+
+	actual, err := QueryRows(db, query)
+	if err != nil {
+		return err
+	}
+
+	if diff := CompareResults(expected, actual, spec); diff != nil {
+		return diff
+	}
+	return nil
+}
+
+// CompareResults compares actual rows with expected rows, returning nil when they match
+func CompareResults(expected, actual [][]any, spec ResultSpec) *ResultDiff {
+	width := 0
+	if len(expected) > 0 {
+		width = len(expected[0])
+	}
+	for _, row := range actual {
+		if len(row) != width && len(expected) > 0 {
+			return &ResultDiff{
+				ExpectedRows: len(expected), ActualRows: len(actual),
+				ExpectedColumns: width, ActualColumns: len(row),
+				OrderMismatch: -1,
+			}
+		}
+	}
+
+	var best *ResultDiff
+	for _, perm := range columnPermutations(width, spec.Columns) {
+		diff := compareWithPermutation(expected, actual, spec, perm)
+		if diff == nil {
+			return nil
+		}
+		if best == nil || len(diff.Missing)+len(diff.Unexpected) < len(best.Missing)+len(best.Unexpected) {
+			best = diff
+		}
+	}
+	return best
+}
+
+// columnPermutations lists the column mappings allowed by the policy
+func columnPermutations(width int, policy ColumnOrder) [][]int {
+	identity := make([]int, width)
+	for i := range identity {
+		identity[i] = i
+	}
+	if policy == ExactColumns || width > maxPermutedColumns {
+		return [][]int{identity}
+	}
+
+	var perms [][]int
+	var rec func(k int)
+	rec = func(k int) {
+		if k == width {
+			perms = append(perms, append([]int(nil), identity...))
+			return
+		}
+		for i := k; i < width; i++ {
+			identity[k], identity[i] = identity[i], identity[k]
+			rec(k + 1)
+			identity[k], identity[i] = identity[i], identity[k]
+		}
+	}
+	rec(0)
+	return perms
+}
+
+// compareWithPermutation compares rows after reordering actual columns by perm
+func compareWithPermutation(expected, actual [][]any, spec ResultSpec, perm []int) *ResultDiff {
+	permuted := make([][]any, len(actual))
+	for i, row := range actual {
+		permuted[i] = make([]any, len(perm))
+		for c, p := range perm {
+			permuted[i][c] = row[p]
+		}
+	}
+
+	diff := &ResultDiff{
+		ExpectedRows: len(expected), ActualRows: len(actual),
+		ExpectedColumns: len(perm), ActualColumns: len(perm),
+		OrderMismatch: -1, Permutation: perm, Tolerance: spec.Tolerance,
+	}
+
+	// Multiset comparison first: which rows are missing or unexpected
+	missing, unexpected := matchRows(expected, permuted, spec)
+	for _, i := range missing {
+		diff.Missing = append(diff.Missing, expected[i])
+	}
+	for _, i := range unexpected {
+		diff.Unexpected = append(diff.Unexpected, actual[i])
+	}
+	if len(diff.Missing) > 0 || len(diff.Unexpected) > 0 {
+		return diff
+	}
+	if !spec.Ordered {
+		return nil
+	}
+
+	// Same rows: walk the ORDER BY groups and find the first row out of place
+	for start := 0; start < len(expected); {
+		end := start + 1
+		for spec.Ties != nil && end < len(expected) && spec.Ties[end] == spec.Ties[start] {
+			end++
+		}
+		if m, u := matchRows(expected[start:end], permuted[start:end], spec); len(m) > 0 || len(u) > 0 {
+			diff.OrderMismatch = start + u[0]
+			if spec.Ties == nil {
+				diff.OrderMismatch = start
+			}
+			diff.ExpectedAt = expected[diff.OrderMismatch]
+			diff.ActualAt = actual[diff.OrderMismatch]
+			return diff
+		}
+		start = end
+	}
+	return nil
+}
+
+// matchRows greedily pairs expected and actual rows, returning the indices left unmatched
+func matchRows(expected, actual [][]any, spec ResultSpec) (missing, unexpected []int) {
+	used := make([]bool, len(actual))
+	for i, e := range expected {
+		found := false
+		for j, a := range actual {
+			if !used[j] && rowEqual(e, a, spec) {
+				used[j] = true
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, i)
+		}
+	}
+	for j := range actual {
+		if !used[j] {
+			unexpected = append(unexpected, j)
+		}
+	}
+	return missing, unexpected
+}
+
+// rowEqual compares two rows column by column
+func rowEqual(expected, actual []any, spec ResultSpec) bool {
+	for c := range expected {
+		if !valueEqual(expected[c], actual[c], spec.Tolerance[c], spec.NullMatchesEmpty) {
+			return false
+		}
+	}
+	return true
+}
+
+// valueEqual compares typed values: numbers compare numerically (within tolerance),
+// text compares exactly, and NULL only matches NULL unless nullMatchesEmpty is set
+func valueEqual(expected, actual any, tolerance float64, nullMatchesEmpty bool) bool {
+	if expected == nil || actual == nil {
+		if expected == nil && actual == nil {
+			return true
+		}
+		if nullMatchesEmpty {
+			return expected == "" || actual == ""
+		}
+		return false
+	}
+
+	en, eNumeric := numericValue(expected)
+	an, aNumeric := numericValue(actual)
+	if eNumeric && aNumeric {
+		return math.Abs(en-an) <= tolerance
+	}
+	if eNumeric || aNumeric {
+		return false
+	}
+	return fmt.Sprint(expected) == fmt.Sprint(actual)
+}
+
+// numericValue converts integer and floating point values to float64
+func numericValue(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// FormatRow formats a row as (a, b, c), quoting text and showing NULL
+func FormatRow(row []any) string {
+	values := make([]string, len(row))
+	for i, v := range row {
+		values[i] = formatValue(v)
+	}
+	return "(" + strings.Join(values, ", ") + ")"
+}
+
+// formatValue formats one value the way FormatRow shows it
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return fmt.Sprintf("%q", v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,119 @@
+package helpers
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareResultsUnordered(t *testing.T) {
+	expected := SingleColumn([]string{"a", "b", "b"})
+	assert.Nil(t, CompareResults(expected, [][]any{{"b"}, {"a"}, {"b"}}, ResultSpec{}))
+
+	diff := CompareResults(expected, [][]any{{"b"}, {"a"}, {"c"}, {"d"}}, ResultSpec{})
+	require.NotNil(t, diff)
+	assert.Equal(t, [][]any{{"b"}}, diff.Missing)
+	assert.Equal(t, [][]any{{"c"}, {"d"}}, diff.Unexpected)
+	assert.Contains(t, diff.Error(), "expected 3 rows, got 4 rows")
+}
+
+func TestCompareResultsOrdered(t *testing.T) {
+	expected := SingleColumn([]string{"a", "b", "c", "d"})
+	spec := ResultSpec{Ordered: true}
+	assert.Nil(t, CompareResults(expected, SingleColumn([]string{"a", "b", "c", "d"}), spec))
+
+	diff := CompareResults(expected, SingleColumn([]string{"a", "c", "b", "d"}), spec)
+	require.NotNil(t, diff)
+	assert.Empty(t, diff.Missing)
+	assert.Equal(t, 1, diff.OrderMismatch)
+
+	// b 与 c 的 ORDER BY 键相同，二者可以交换
+	spec.Ties = []int{0, 1, 1, 2}
+	assert.Nil(t, CompareResults(expected, SingleColumn([]string{"a", "c", "b", "d"}), spec))
+	diff = CompareResults(expected, SingleColumn([]string{"a", "b", "d", "c"}), spec)
+	require.NotNil(t, diff)
+	assert.Equal(t, 2, diff.OrderMismatch)
+}
+
+func TestCompareResultsColumns(t *testing.T) {
+	expected := [][]any{{"Inception", 2010, 8.8}, {"Up", 2009, 8.3}}
+	swapped := [][]any{{int64(2010), "Inception", 8.8}, {int64(2009), "Up", 8.3}}
+
+	diff := CompareResults(expected, swapped, ResultSpec{})
+	require.NotNil(t, diff)
+	assert.Nil(t, CompareResults(expected, swapped, ResultSpec{Columns: AnyColumnOrder}))
+
+	// 每一行都必须使用相同的列顺序
+	mixed := [][]any{{"Inception", int64(2010), 8.8}, {int64(2009), "Up", 8.3}}
+	assert.NotNil(t, CompareResults(expected, mixed, ResultSpec{Columns: AnyColumnOrder}))
+
+	diff = CompareResults(expected, [][]any{{"Inception", int64(2010)}}, ResultSpec{})
+	require.NotNil(t, diff)
+	assert.Equal(t, "expected 3 columns, got 2 columns", diff.Error())
+}
+
+func TestCompareResultsValues(t *testing.T) {
+	// 数字按数值比较，文本与数字不相等
+	assert.Nil(t, CompareResults([][]any{{1988}}, [][]any{{int64(1988)}}, ResultSpec{}))
+	assert.NotNil(t, CompareResults([][]any{{1988}}, [][]any{{"1988"}}, ResultSpec{}))
+
+	tolerance := ResultSpec{Tolerance: map[int]float64{0: 0.01}}
+	assert.Nil(t, CompareResults([][]any{{0.65906}}, [][]any{{0.66}}, tolerance))
+	assert.NotNil(t, CompareResults([][]any{{0.65906}}, [][]any{{0.67}}, tolerance))
+
+	assert.Nil(t, CompareResults([][]any{{nil}}, [][]any{{nil}}, ResultSpec{}))
+	assert.NotNil(t, CompareResults([][]any{{nil}}, [][]any{{""}}, ResultSpec{}))
+	assert.Nil(t, CompareResults([][]any{{nil}}, [][]any{{""}}, ResultSpec{NullMatchesEmpty: true}))
+}
+
+func TestQueryRows(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	rows, err := QueryRows(db, "SELECT 'a', 1, 2.5, NULL")
+	require.NoError(t, err)
+	assert.Equal(t, [][]any{{"a", int64(1), 2.5, nil}}, rows)
+
+	_, err = QueryRows(db, "SELECT * FROM missing")
+	assert.ErrorContains(t, err, "no such table")
+}
+
+func TestResultDiffError(t *testing.T) {
+	var expected []string
+	var actual []string
+	for _, c := range "abcdefgh" {
+		expected = append(expected, string(c))
+	}
+	actual = append(actual, expected[7:]...)
+	actual = append(actual, "x")
+
+	diff := CompareResults(SingleColumn(expected), SingleColumn(actual), ResultSpec{})
+	require.NotNil(t, diff)
+	assert.Equal(t, `result mismatch: expected 8 rows, got 2 rows
+7 missing row(s):
+  ("a")
+  ("b")
+  ("c")
+  ("d")
+  ("e")
+  ... and 2 more
+1 unexpected row(s):
+  ("x")`, diff.Error())
+
+	diff = CompareResults(SingleColumn(expected[:3]), SingleColumn([]string{"a", "c", "b"}), ResultSpec{Ordered: true})
+	require.NotNil(t, diff)
+	assert.Equal(t, `result mismatch: rows are in the wrong order, first difference at row 2: expected ("b"), got ("c")`, diff.Error())
+
+	diff = CompareResults([][]any{{0.65906}}, [][]any{{0.7}}, ResultSpec{Tolerance: map[int]float64{0: 0.01}})
+	require.NotNil(t, diff)
+	assert.Equal(t, "result mismatch: expected 1 rows, got 1 rows\ncolumn 1: got 0.7, expected 0.65906 ± 0.01 (0.64906 to 0.66906)"+
+		"\n1 missing row(s):\n  (0.65906)\n1 unexpected row(s):\n  (0.7)", diff.Error())
+
+	diff = CompareResults([][]any{{0.65906}}, [][]any{{"0.65906"}}, ResultSpec{Tolerance: map[int]float64{0: 0.01}})
+	require.NotNil(t, diff)
+	assert.Contains(t, diff.Error(), `column 1: got "0.65906", expected 0.65906 ± 0.01`)
+}
@@ -0,0 +1,135 @@
+package helpers
+
+import (
+	"database/sql"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// LogStatement is one statement of a replayed SQL log
+type LogStatement struct {
+	// Line is the 1-based line on which the statement starts
+	Line int
+	SQL  string
+	// Tables are the database tables the statement mentions, in lower case
+	Tables []string
+	Rows   int
+	Err    error
+}
+
+// SQLLogReport is the outcome of replaying a SQL log such as fiftyville/log.sql
+type SQLLogReport struct {
+	Statements []LogStatement
+	// ShellCommands counts sqlite3 dot-commands (.schema, .tables, ...),
+	// which are skipped rather than run
+	ShellCommands int
+}
+
+// Valid counts the statements that ran without error
+func (r *SQLLogReport) Valid() int {
+	n := 0
+	for _, s := range r.Statements {
+		if s.Err == nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Failing returns the statements that could not be run
+func (r *SQLLogReport) Failing() []LogStatement {
+	var failing []LogStatement
+	for _, s := range r.Statements {
+		if s.Err != nil {
+			failing = append(failing, s)
+		}
+	}
+	return failing
+}
+
+// Tables returns the tables mentioned by the statements that ran, sorted
+func (r *SQLLogReport) Tables() []string {
+	seen := map[string]bool{}
+	for _, s := range r.Statements {
+		if s.Err != nil {
+			continue
+		}
+		for _, t := range s.Tables {
+			seen[t] = true
+		}
+	}
+	tables := make([]string, 0, len(seen))
+	for t := range seen {
+		tables = append(tables, t)
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// MissingTables returns the required tables no valid statement mentions
+func (r *SQLLogReport) MissingTables(required []string) []string {
+	touched := map[string]bool{}
+	for _, t := range r.Tables() {
+		touched[t] = true
+	}
+	var missing []string
+	for _, t := range required {
+		if !touched[strings.ToLower(t)] {
+			missing = append(missing, t)
+		}
+	}
+	return missing
+}
+
+// ReplaySQLLog runs every statement of a SQL log against db, which should be
+// opened read-only. Only SELECT queries are run; any other statement counts as
+// failing. Lines starting with "." are sqlite3 shell commands and are skipped.
+func ReplaySQLLog(db *sql.DB, script string) (*SQLLogReport, error) {
+	tables, err := tableNames(db)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &SQLLogReport{}
+	lines := strings.Split(script, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), ".") {
+			report.ShellCommands++
+			lines[i] = ""
+		}
+	}
+	script = strings.Join(lines, "\n")
+
+	for _, stmt := range splitSQLScript(script) {
+		s := LogStatement{Line: stmt.line, SQL: stmt.text}
+		for _, t := range TokenizeSQL(stmt.text) {
+			name := strings.ToLower(t.Text)
+			if (t.Kind == SQLWord || t.Kind == SQLQuotedIdentifier) && tables[name] && !slices.Contains(s.Tables, name) {
+				s.Tables = append(s.Tables, name)
+			}
+		}
+		if s.Err = ValidateSQLQuery(stmt.text); s.Err == nil {
+			var rows [][]any
+			rows, s.Err = QueryRows(db, stmt.text)
+			s.Rows = len(rows)
+		}
+		report.Statements = append(report.Statements, s)
+	}
+	return report, nil
+}
+
+// tableNames returns the lower-case names of the tables in db
+func tableNames(db *sql.DB) (map[string]bool, error) {
+	rows, err := QueryRows(db, "SELECT name FROM sqlite_master WHERE type = 'table'")
+	if err != nil {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, row := range rows {
+		if name, ok := row[0].(string); ok {
+			names[strings.ToLower(name)] = true
+		}
+	}
+	return names, nil
+}
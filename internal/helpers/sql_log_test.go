@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplaySQLLog(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec(`CREATE TABLE crime_scene_reports (id INTEGER, street TEXT, description TEXT);
+		CREATE TABLE interviews (id INTEGER, name TEXT, transcript TEXT);
+		CREATE TABLE people (id INTEGER, name TEXT);
+		INSERT INTO crime_scene_reports VALUES (1, 'Humphrey Street', 'Theft of the CS50 duck; took place at 10:15am.');
+		INSERT INTO interviews VALUES (1, 'Ruth', 'Within ten minutes of the theft...');`)
+	require.NoError(t, err)
+
+	log := `-- Keep a log of any SQL queries you execute as you solve the mystery.
+.schema
+
+-- the report mentions a semicolon: 'a;b'
+SELECT description FROM crime_scene_reports
+WHERE street = 'Humphrey Street';
+
+SELECT transcript FROM "interviews" WHERE transcript LIKE '%theft%';
+SELECT name FROM suspects;
+DELETE FROM people;
+`
+	report, err := ReplaySQLLog(db, log)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, report.ShellCommands)
+	require.Len(t, report.Statements, 4)
+	assert.Equal(t, 5, report.Statements[0].Line)
+	assert.Equal(t, 1, report.Statements[0].Rows)
+	assert.Equal(t, 8, report.Statements[1].Line)
+	assert.Equal(t, 2, report.Valid())
+
+	failing := report.Failing()
+	require.Len(t, failing, 2)
+	assert.Equal(t, 9, failing[0].Line)
+	assert.Contains(t, failing[0].Err.Error(), "no such table: suspects")
+	assert.EqualError(t, failing[1].Err, "only SELECT queries are allowed, found a DELETE statement")
+
+	// people is only mentioned by the failing DELETE
+	assert.Equal(t, []string{"crime_scene_reports", "interviews"}, report.Tables())
+	assert.Equal(t, []string{"people"}, report.MissingTables([]string{"interviews", "people"}))
+}
@@ -0,0 +1,83 @@
+package helpers
+
+import (
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/bootllm/tester-utils/random"
+)
+
+// SQLPerturbEnabled reports whether SQL checks are repeated on a perturbed
+// database copy (BOOTLLM_SQL_PERTURB=1)
+func SQLPerturbEnabled() bool {
+	return os.Getenv("BOOTLLM_SQL_PERTURB") == "1"
+}
+
+// SeedFromEnv returns the seed in the named environment variable, or a random
+// seed when it is unset, so that a reported failure can be reproduced
+func SeedFromEnv(name string) int64 {
+	if seed, err := strconv.ParseInt(os.Getenv(name), 10, 64); err == nil {
+		return seed
+	}
+	return int64(random.RandomInt(1, 1<<30))
+}
+
+// CopyDatabase copies a SQLite database file
+func CopyDatabase(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// PerturbDatabase copies src to dst and applies the statements to the copy in
+// one transaction; src is never modified
+func PerturbDatabase(src, dst string, statements []string) error {
+	if err := CopyDatabase(src, dst); err != nil {
+		return fmt.Errorf("could not copy database: %v", err)
+	}
+
+	db, err := sql.Open("sqlite3", dst)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("perturbation failed: %v\n%s", err, stmt)
+		}
+	}
+	return tx.Commit()
+}
+
+// SQLQuote quotes a string as a SQL literal
+func SQLQuote(s string) string {
+	quoted := []byte{'\''}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\'' {
+			quoted = append(quoted, '\'')
+		}
+		quoted = append(quoted, s[i])
+	}
+	return string(append(quoted, '\''))
+}
@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerturbDatabase(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "movies.db")
+	dst := filepath.Join(dir, "perturbed.db")
+
+	db, err := sql.Open("sqlite3", src)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE people (id INTEGER, name TEXT, birth NUMERIC); INSERT INTO people VALUES (1, 'Emma Stone', 1988);")
+	require.NoError(t, err)
+	db.Close()
+
+	require.NoError(t, PerturbDatabase(src, dst, []string{
+		"UPDATE people SET birth = birth + 2",
+		"INSERT INTO people VALUES (2, " + SQLQuote("Conan O'Brien") + ", 1963)",
+	}))
+
+	for path, expected := range map[string][][]any{
+		src: {{"Emma Stone", int64(1988)}},
+		dst: {{"Emma Stone", int64(1990)}, {"Conan O'Brien", int64(1963)}},
+	} {
+		db, err := sql.Open("sqlite3", path)
+		require.NoError(t, err)
+		rows, err := QueryRows(db, "SELECT name, birth FROM people ORDER BY id")
+		require.NoError(t, err)
+		assert.Equal(t, expected, rows, path)
+		db.Close()
+	}
+
+	// 失败的扰动整体回滚，并指出出错的语句
+	err = PerturbDatabase(src, dst, []string{"UPDATE people SET birth = 0", "UPDATE missing SET x = 1"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "UPDATE missing")
+}
+
+func TestSQLQuote(t *testing.T) {
+	assert.Equal(t, "'Director''s Cut'", SQLQuote("Director's Cut"))
+}
@@ -0,0 +1,78 @@
+package helpers
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// ReferenceResult runs a staff reference query and returns its rows as the
+// expected result. The last orderKeys columns of the reference query are the
+// ORDER BY keys: they are stripped from the rows, and consecutive rows with
+// equal keys are put in the same Ties group so they may appear in any order.
+func ReferenceResult(db *sql.DB, query string, orderKeys int) (rows [][]any, ties []int, err error) {
+	rows, err = QueryRows(db, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("reference query failed: %v", err)
+	}
+	if orderKeys == 0 {
+		return rows, nil, nil
+	}
+
+	ties = make([]int, len(rows))
+	var previous []any
+	for i, row := range rows {
+		if len(row) <= orderKeys {
+			return nil, nil, fmt.Errorf("reference query returns %d columns, expected more than %d order keys", len(row), orderKeys)
+		}
+		width := len(row) - orderKeys
+		keys := row[width:]
+		switch {
+		case i == 0:
+		case rowEqual(previous, keys, ResultSpec{}):
+			ties[i] = ties[i-1]
+		default:
+			ties[i] = ties[i-1] + 1
+		}
+		previous = keys
+		rows[i] = row[:width]
+	}
+	return rows, ties, nil
+}
+
+// TestSQLReference compares the query in filename with staff reference queries
+// run against the same database; matching any one of them passes. When none
+// matches, the diff against the closest reference is reported. The analysis
+// of the student query is returned for structural checks.
+func TestSQLReference(db *sql.DB, workDir, filename string, references []string, orderKeys int, spec ResultSpec) (*QueryAnalysis, error) {
+	query, err := ReadSQLFile(workDir, filename)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateSQLQuery(query); err != nil {
+		return nil, err
+	}
+	analysis, err := AnalyzeSQLQuery(db, query)
+	if err != nil {
+		return nil, err
+	}
+
+	var closest *ResultDiff
+	for _, reference := range references {
+		expected, ties, err := ReferenceResult(db, reference, orderKeys)
+		if err != nil {
+			return nil, err
+		}
+		spec.Ties = ties
+		diff := CompareResults(expected, analysis.Rows, spec)
+		if diff == nil {
+			return analysis, nil
+		}
+		if closest == nil || len(diff.Missing)+len(diff.Unexpected) < len(closest.Missing)+len(closest.Unexpected) {
+			closest = diff
+		}
+	}
+	if len(references) > 1 {
+		return analysis, fmt.Errorf("result does not match any of the %d accepted answers; closest: %v", len(references), closest)
+	}
+	return analysis, closest
+}
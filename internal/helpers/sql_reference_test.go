@@ -0,0 +1,75 @@
+package helpers
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func openPeopleDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	_, err = db.Exec(`
+		CREATE TABLE people (id INTEGER, name TEXT, birth NUMERIC);
+		INSERT INTO people VALUES (1, 'Ann', 1970), (2, 'Ben', 1960), (3, 'Cat', 1970), (4, 'Dan', 1980);`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestReferenceResult(t *testing.T) {
+	db := openPeopleDB(t)
+
+	rows, ties, err := ReferenceResult(db, "SELECT name, birth FROM people ORDER BY birth, name", 1)
+	require.NoError(t, err)
+	assert.Equal(t, SingleColumn([]string{"Ben", "Ann", "Cat", "Dan"}), rows)
+	assert.Equal(t, []int{0, 1, 1, 2}, ties)
+
+	rows, ties, err = ReferenceResult(db, "SELECT name FROM people WHERE birth = 1970", 0)
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+	assert.Nil(t, ties)
+
+	_, _, err = ReferenceResult(db, "SELECT name FROM people", 1)
+	assert.Error(t, err)
+}
+
+func TestSQLReferenceAcceptsTies(t *testing.T) {
+	db := openPeopleDB(t)
+	dir := t.TempDir()
+	reference := "SELECT name, birth FROM people ORDER BY birth"
+	spec := ResultSpec{Ordered: true}
+
+	// 同年出生的 Ann 与 Cat 顺序任意
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "9.sql"), []byte("SELECT name FROM people ORDER BY birth, name DESC;"), 0644))
+	assert.NoError(t, testSQLReference(db, dir, "9.sql", []string{reference}, 1, spec))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "9.sql"), []byte("SELECT name FROM people ORDER BY name;"), 0644))
+	assert.Error(t, testSQLReference(db, dir, "9.sql", []string{reference}, 1, spec))
+}
+
+func TestSQLReferenceAlternatives(t *testing.T) {
+	db := openPeopleDB(t)
+	dir := t.TempDir()
+	references := []string{"SELECT name FROM people WHERE birth = 1970", "SELECT name FROM people WHERE birth = 1980"}
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "12.sql"), []byte("SELECT name FROM people WHERE id = 4"), 0644))
+	assert.NoError(t, testSQLReference(db, dir, "12.sql", references, 0, ResultSpec{}))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "12.sql"), []byte("SELECT name FROM people WHERE id IN (1, 2)"), 0644))
+	err := testSQLReference(db, dir, "12.sql", references, 0, ResultSpec{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "any of the 2 accepted answers")
+	assert.Contains(t, err.Error(), `("Cat")`)
+}
+
+// testSQLReference discards the analysis returned by TestSQLReference
+func testSQLReference(db *sql.DB, workDir, filename string, references []string, orderKeys int, spec ResultSpec) error {
+	_, err := TestSQLReference(db, workDir, filename, references, orderKeys, spec)
+	return err
+}
@@ -0,0 +1,171 @@
+package helpers
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// SQLQueryTimeout is the time limit for a single query
+	SQLQueryTimeout = 10 * time.Second
+
+	// MaxResultRows caps the number of rows read from a single query
+	MaxResultRows = 100000
+)
+
+// OpenReadOnlyDB opens a SQLite database in read-only, immutable, query-only mode
+func OpenReadOnlyDB(path string) (*sql.DB, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(abs); err != nil {
+		return nil, err
+	}
+	dsn := (&url.URL{Scheme: "file", Path: abs, RawQuery: "mode=ro&immutable=1&_query_only=1"}).String()
+	return sql.Open("sqlite3", dsn)
+}
+
+// OpenSandboxedDB copies the database to a temporary directory and opens the
+// copy read-only, so student queries can never modify the original file.
+// The returned cleanup closes the database and removes the copy.
+func OpenSandboxedDB(path string) (*sql.DB, func(), error) {
+	dir, err := os.MkdirTemp("", "sql-sandbox-")
+	if err != nil {
+		return nil, nil, err
+	}
+	copyPath := filepath.Join(dir, filepath.Base(path))
+	if err := CopyDatabase(path, copyPath); err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	db, err := OpenReadOnlyDB(copyPath)
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, nil, err
+	}
+	return db, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}, nil
+}
+
+// ValidateSQLQuery accepts exactly one SELECT (or WITH ... SELECT) statement;
+// a trailing semicolon and comments are allowed
+func ValidateSQLQuery(query string) error {
+	statements := splitSQLStatements(query)
+	if len(statements) == 0 {
+		return fmt.Errorf("file contains no SQL query")
+	}
+	if len(statements) > 1 {
+		return fmt.Errorf("file contains %d SQL statements; write exactly one SELECT query", len(statements))
+	}
+	keyword := strings.ToUpper(firstWord(statements[0]))
+	if keyword != "SELECT" && keyword != "WITH" {
+		return fmt.Errorf("only SELECT queries are allowed, found a %s statement", keyword)
+	}
+	return nil
+}
+
+// splitSQLStatements splits a script on semicolons outside string literals,
+// quoted identifiers and comments, dropping comments and empty statements
+func splitSQLStatements(script string) []string {
+	var statements []string
+	for _, s := range splitSQLScript(script) {
+		statements = append(statements, s.text)
+	}
+	return statements
+}
+
+// scriptStatement is a statement of a script with the line it starts on
+type scriptStatement struct {
+	line int
+	text string
+}
+
+// splitSQLScript is splitSQLStatements, also recording the 1-based line on
+// which each statement starts
+func splitSQLScript(script string) []scriptStatement {
+	var statements []scriptStatement
+	var current strings.Builder
+	line, startLine := 1, 0
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			statements = append(statements, scriptStatement{startLine, s})
+		}
+		current.Reset()
+		startLine = 0
+	}
+	// begin records the line of the first non-comment character of a statement
+	begin := func() {
+		if startLine == 0 {
+			startLine = line
+		}
+	}
+
+	for i := 0; i < len(script); i++ {
+		c := script[i]
+		switch {
+		case c == '-' && i+1 < len(script) && script[i+1] == '-':
+			for i+1 < len(script) && script[i+1] != '\n' {
+				i++
+			}
+			current.WriteByte(' ')
+		case c == '/' && i+1 < len(script) && script[i+1] == '*':
+			end := strings.Index(script[i+2:], "*/")
+			if end < 0 {
+				end = len(script) - i - 3
+			}
+			line += strings.Count(script[i:i+end+3], "\n")
+			i += end + 3
+			current.WriteByte(' ')
+		case c == '\'' || c == '"' || c == '`' || c == '[':
+			begin()
+			closing := c
+			if c == '[' {
+				closing = ']'
+			}
+			start := i
+			for i++; i < len(script); i++ {
+				if script[i] == closing {
+					// 'it''s' style escapes
+					if closing != ']' && i+1 < len(script) && script[i+1] == closing {
+						i++
+						continue
+					}
+					break
+				}
+			}
+			literal := script[start:min(i+1, len(script))]
+			line += strings.Count(literal, "\n")
+			current.WriteString(literal)
+		case c == ';':
+			flush()
+		default:
+			if c == '\n' {
+				line++
+			} else if c != ' ' && c != '\t' && c != '\r' {
+				begin()
+			}
+			current.WriteByte(c)
+		}
+	}
+	flush()
+	return statements
+}
+
+// firstWord returns the first keyword of a statement
+func firstWord(statement string) string {
+	fields := strings.FieldsFunc(statement, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '_')
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,66 @@
+package helpers
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSQLQuery(t *testing.T) {
+	for _, query := range []string{
+		"SELECT name FROM songs;",
+		"select name from songs",
+		"-- all songs\nSELECT name FROM songs; -- done\n",
+		"/* 2008 */ SELECT title FROM movies WHERE title = 'a;b' AND title != 'it''s;';",
+		"WITH t AS (SELECT 1) SELECT * FROM t;",
+	} {
+		assert.NoError(t, ValidateSQLQuery(query), query)
+	}
+
+	err := ValidateSQLQuery("DELETE FROM songs;")
+	assert.EqualError(t, err, "only SELECT queries are allowed, found a DELETE statement")
+
+	err = ValidateSQLQuery("SELECT 1; DROP TABLE songs;")
+	assert.EqualError(t, err, "file contains 2 SQL statements; write exactly one SELECT query")
+
+	err = ValidateSQLQuery("-- TODO\n;")
+	assert.EqualError(t, err, "file contains no SQL query")
+}
+
+func TestOpenSandboxedDB(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "songs.db")
+	db, err := sql.Open("sqlite3", path)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE songs (name TEXT); INSERT INTO songs VALUES ('a'), ('b');")
+	require.NoError(t, err)
+	db.Close()
+	before, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	sandbox, cleanup, err := OpenSandboxedDB(path)
+	require.NoError(t, err)
+	_, err = sandbox.Exec("DELETE FROM songs")
+	assert.Error(t, err)
+	rows, err := QueryRows(sandbox, "SELECT name FROM songs")
+	require.NoError(t, err)
+	assert.Len(t, rows, 2)
+	cleanup()
+
+	after, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, before, after)
+}
+
+func TestQueryRowsLimits(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	_, err = QueryRows(db, "WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n) SELECT i FROM n")
+	assert.EqualError(t, err, "query returned more than 100000 rows")
+}
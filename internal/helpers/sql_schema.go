@@ -0,0 +1,295 @@
+package helpers
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// SchemaColumn is a column reported by PRAGMA table_info
+type SchemaColumn struct {
+	Name    string
+	Type    string
+	NotNull bool
+	// PrimaryKey is the 1-based position in the primary key, or 0
+	PrimaryKey int
+}
+
+// ForeignKey is a foreign key reported by PRAGMA foreign_key_list
+type ForeignKey struct {
+	Table string
+	From  string
+	// To is the referenced column; empty when it is the primary key of References
+	References string
+	To         string
+}
+
+// SchemaTable is one table of an inspected database
+type SchemaTable struct {
+	Name        string
+	Columns     []SchemaColumn
+	ForeignKeys []ForeignKey
+	Rows        int
+}
+
+// Schema is the structure of a database as read from sqlite_master and PRAGMAs
+type Schema struct {
+	Tables []SchemaTable
+}
+
+// QuoteIdentifier quotes a table or column name for use in a query
+func QuoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// ApplySQLScript runs each statement of a script, reporting the statement that fails
+func ApplySQLScript(db *sql.DB, script string) error {
+	for _, s := range splitSQLScript(script) {
+		if _, err := db.Exec(s.text); err != nil {
+			return fmt.Errorf("line %d: %v\n%s", s.line, err, s.text)
+		}
+	}
+	return nil
+}
+
+// InspectSchema reads the user tables of db with their columns, foreign keys and row counts
+func InspectSchema(db *sql.DB) (*Schema, error) {
+	names, err := QueryRows(db, "SELECT name FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%' ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+
+	schema := &Schema{}
+	for _, row := range names {
+		t := SchemaTable{Name: fmt.Sprint(row[0])}
+		quoted := QuoteIdentifier(t.Name)
+
+		columns, err := QueryRows(db, "PRAGMA table_info("+quoted+")")
+		if err != nil {
+			return nil, err
+		}
+		// cid, name, type, notnull, dflt_value, pk
+		for _, c := range columns {
+			t.Columns = append(t.Columns, SchemaColumn{
+				Name:       fmt.Sprint(c[1]),
+				Type:       fmt.Sprint(c[2]),
+				NotNull:    c[3] == int64(1),
+				PrimaryKey: int(c[5].(int64)),
+			})
+		}
+
+		keys, err := QueryRows(db, "PRAGMA foreign_key_list("+quoted+")")
+		if err != nil {
+			return nil, err
+		}
+		// id, seq, table, from, to, on_update, on_delete, match
+		for _, k := range keys {
+			fk := ForeignKey{Table: t.Name, References: fmt.Sprint(k[2]), From: fmt.Sprint(k[3])}
+			if k[4] != nil {
+				fk.To = fmt.Sprint(k[4])
+			}
+			t.ForeignKeys = append(t.ForeignKeys, fk)
+		}
+
+		count, err := QueryRows(db, "SELECT COUNT(*) FROM "+quoted)
+		if err != nil {
+			return nil, err
+		}
+		t.Rows = int(count[0][0].(int64))
+		schema.Tables = append(schema.Tables, t)
+	}
+	return schema, nil
+}
+
+// Table returns the named table (case-insensitively), or nil
+func (s *Schema) Table(name string) *SchemaTable {
+	for i := range s.Tables {
+		if strings.EqualFold(s.Tables[i].Name, name) {
+			return &s.Tables[i]
+		}
+	}
+	return nil
+}
+
+// TableNames returns the names of all tables
+func (s *Schema) TableNames() []string {
+	names := make([]string, len(s.Tables))
+	for i, t := range s.Tables {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// PrimaryKey returns the primary key columns of a table in key order
+// (an INTEGER PRIMARY KEY or a composite key); empty when there is none
+func (t *SchemaTable) PrimaryKey() []string {
+	var columns []SchemaColumn
+	for _, c := range t.Columns {
+		if c.PrimaryKey > 0 {
+			columns = append(columns, c)
+		}
+	}
+	sort.Slice(columns, func(i, j int) bool { return columns[i].PrimaryKey < columns[j].PrimaryKey })
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.Name
+	}
+	return names
+}
+
+// ColumnRef names a column of a table
+type ColumnRef struct {
+	Table  string
+	Column string
+}
+
+func (c ColumnRef) String() string {
+	return c.Table + "." + c.Column
+}
+
+// FindColumns returns every column whose distinct non-NULL values are
+// exactly values (compared as text)
+func FindColumns(db *sql.DB, schema *Schema, values []string) ([]ColumnRef, error) {
+	want := map[string]bool{}
+	for _, v := range values {
+		want[v] = true
+	}
+
+	var found []ColumnRef
+	for _, t := range schema.Tables {
+		for _, c := range t.Columns {
+			rows, err := QueryRows(db, fmt.Sprintf("SELECT DISTINCT %s FROM %s WHERE %[1]s IS NOT NULL",
+				QuoteIdentifier(c.Name), QuoteIdentifier(t.Name)))
+			if err != nil {
+				return nil, err
+			}
+			if len(rows) != len(want) {
+				continue
+			}
+			match := true
+			for _, row := range rows {
+				if !want[fmt.Sprint(row[0])] {
+					match = false
+					break
+				}
+			}
+			if match {
+				found = append(found, ColumnRef{t.Name, c.Name})
+			}
+		}
+	}
+	return found, nil
+}
+
+// joinEdge is a foreign key seen from one of its two tables
+type joinEdge struct {
+	key  ForeignKey
+	from string
+	to   string
+}
+
+// referencedColumn returns the column a foreign key points at, resolving an
+// omitted column to the single-column primary key of the referenced table
+func (s *Schema) referencedColumn(fk ForeignKey) (string, bool) {
+	if fk.To != "" {
+		return fk.To, true
+	}
+	t := s.Table(fk.References)
+	if t == nil {
+		return "", false
+	}
+	if pk := t.PrimaryKey(); len(pk) == 1 {
+		return pk[0], true
+	}
+	return "", false
+}
+
+// JoinQuery builds a query selecting the given columns from their tables,
+// joined along declared foreign keys. It fails when some table cannot be
+// reached from the first one through foreign keys.
+func (s *Schema) JoinQuery(columns []ColumnRef) (string, error) {
+	if len(columns) == 0 {
+		return "", fmt.Errorf("no columns to select")
+	}
+
+	// foreign keys are followed in both directions
+	edges := map[string][]joinEdge{}
+	for _, t := range s.Tables {
+		for _, fk := range t.ForeignKeys {
+			if s.Table(fk.References) == nil {
+				continue
+			}
+			from, to := strings.ToLower(fk.Table), strings.ToLower(fk.References)
+			edges[from] = append(edges[from], joinEdge{fk, from, to})
+			edges[to] = append(edges[to], joinEdge{fk, to, from})
+		}
+	}
+
+	root := strings.ToLower(columns[0].Table)
+	via := map[string]joinEdge{root: {}}
+	queue := []string{root}
+	for len(queue) > 0 {
+		table := queue[0]
+		queue = queue[1:]
+		for _, e := range edges[table] {
+			if _, seen := via[e.to]; !seen {
+				via[e.to] = e
+				queue = append(queue, e.to)
+			}
+		}
+	}
+
+	// walk back from each selected table to the root, collecting the joins needed
+	var joins []joinEdge
+	joined := map[string]bool{root: true}
+	for _, c := range columns[1:] {
+		table := strings.ToLower(c.Table)
+		var path []joinEdge
+		for !joined[table] {
+			e, ok := via[table]
+			if !ok {
+				return "", fmt.Errorf("no foreign key relationship connects %s and %s", columns[0].Table, c.Table)
+			}
+			path = append(path, e)
+			joined[table] = true
+			table = e.from
+		}
+		for i := len(path) - 1; i >= 0; i-- {
+			joins = append(joins, path[i])
+		}
+	}
+
+	selected := make([]string, len(columns))
+	for i, c := range columns {
+		selected[i] = QuoteIdentifier(c.Table) + "." + QuoteIdentifier(c.Column)
+	}
+	query := "SELECT " + strings.Join(selected, ", ") + " FROM " + QuoteIdentifier(s.Table(columns[0].Table).Name)
+	for _, e := range joins {
+		to, ok := s.referencedColumn(e.key)
+		if !ok {
+			return "", fmt.Errorf("foreign key %s.%s references %s without a single-column primary key",
+				e.key.Table, e.key.From, e.key.References)
+		}
+		table := s.Table(e.to).Name
+		query += fmt.Sprintf(" JOIN %s ON %s.%s = %s.%s", QuoteIdentifier(table),
+			QuoteIdentifier(e.key.Table), QuoteIdentifier(e.key.From),
+			QuoteIdentifier(s.Table(e.key.References).Name), QuoteIdentifier(to))
+	}
+	return query, nil
+}
+
+// ForeignKeyViolations returns the rows reported by PRAGMA foreign_key_check
+// as "table row N references missing parent" messages
+func ForeignKeyViolations(db *sql.DB) ([]string, error) {
+	rows, err := QueryRows(db, "PRAGMA foreign_key_check")
+	if err != nil {
+		return nil, err
+	}
+	// table, rowid, parent, fkid
+	violations := make([]string, len(rows))
+	for i, r := range rows {
+		violations[i] = fmt.Sprintf("%v row %v references a missing row of %v", r[0], r[1], r[2])
+	}
+	return violations, nil
+}
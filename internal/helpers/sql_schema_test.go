@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"database/sql"
+	"testing"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const rosterSchema = `
+CREATE TABLE students (id INTEGER, student_name TEXT NOT NULL, PRIMARY KEY(id));
+CREATE TABLE houses (id INTEGER PRIMARY KEY, house TEXT, head TEXT);
+-- one row per student
+CREATE TABLE assignments (
+    student_id INTEGER REFERENCES students,
+    house_id INTEGER,
+    PRIMARY KEY(student_id, house_id),
+    FOREIGN KEY(house_id) REFERENCES houses(id)
+);
+CREATE TABLE notes (text TEXT);
+`
+
+func openRoster(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, ApplySQLScript(db, rosterSchema))
+	_, err = db.Exec(`INSERT INTO students VALUES (1, 'Hannah'), (2, 'Harry'), (3, 'Luna');
+		INSERT INTO houses VALUES (1, 'Hufflepuff', 'Sprout'), (2, 'Gryffindor', 'McGonagall'), (3, 'Ravenclaw', 'Flitwick');
+		INSERT INTO assignments VALUES (1, 1), (2, 2), (3, 3);`)
+	require.NoError(t, err)
+	return db
+}
+
+func TestApplySQLScript(t *testing.T) {
+	db, err := sql.Open("sqlite3", ":memory:")
+	require.NoError(t, err)
+	defer db.Close()
+
+	err = ApplySQLScript(db, "CREATE TABLE a (id INTEGER);\n\nCREATE TABLE a (id INTEGER);")
+	assert.EqualError(t, err, "line 3: table a already exists\nCREATE TABLE a (id INTEGER)")
+}
+
+func TestInspectSchema(t *testing.T) {
+	schema, err := InspectSchema(openRoster(t))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"assignments", "houses", "notes", "students"}, schema.TableNames())
+	assert.Equal(t, []string{"id"}, schema.Table("Students").PrimaryKey())
+	assert.Equal(t, []string{"student_id", "house_id"}, schema.Table("assignments").PrimaryKey())
+	assert.Empty(t, schema.Table("notes").PrimaryKey())
+	assert.Nil(t, schema.Table("people"))
+
+	assignments := schema.Table("assignments")
+	assert.Equal(t, 3, assignments.Rows)
+	assert.ElementsMatch(t, []ForeignKey{
+		{Table: "assignments", From: "student_id", References: "students"},
+		{Table: "assignments", From: "house_id", References: "houses", To: "id"},
+	}, assignments.ForeignKeys)
+	assert.True(t, schema.Table("students").Columns[1].NotNull)
+}
+
+func TestFindColumnsAndJoinQuery(t *testing.T) {
+	db := openRoster(t)
+	schema, err := InspectSchema(db)
+	require.NoError(t, err)
+
+	names, err := FindColumns(db, schema, []string{"Harry", "Hannah", "Luna"})
+	require.NoError(t, err)
+	assert.Equal(t, []ColumnRef{{"students", "student_name"}}, names)
+	missing, err := FindColumns(db, schema, []string{"Harry", "Hannah"})
+	require.NoError(t, err)
+	assert.Empty(t, missing)
+
+	query, err := schema.JoinQuery([]ColumnRef{{"students", "student_name"}, {"houses", "house"}, {"houses", "head"}})
+	require.NoError(t, err)
+	rows, err := QueryRows(db, query)
+	require.NoError(t, err)
+	assert.Nil(t, CompareResults([][]any{
+		{"Harry", "Gryffindor", "McGonagall"},
+		{"Hannah", "Hufflepuff", "Sprout"},
+		{"Luna", "Ravenclaw", "Flitwick"},
+	}, rows, ResultSpec{}))
+
+	_, err = schema.JoinQuery([]ColumnRef{{"students", "student_name"}, {"notes", "text"}})
+	assert.EqualError(t, err, "no foreign key relationship connects students and notes")
+}
+
+func TestForeignKeyViolations(t *testing.T) {
+	db := openRoster(t)
+	violations, err := ForeignKeyViolations(db)
+	require.NoError(t, err)
+	assert.Empty(t, violations)
+
+	_, err = db.Exec("INSERT INTO assignments VALUES (4, 9)")
+	require.NoError(t, err)
+	violations, err = ForeignKeyViolations(db)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{
+		"assignments row 4 references a missing row of students",
+		"assignments row 4 references a missing row of houses",
+	}, violations)
+}
@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// WAVHeaderSize 是 volume 按原样复制的 WAV 头长度
+const WAVHeaderSize = 44
+
+// wavHeaderFields 是 44 字节 WAV 头中各字段的起始偏移，用于报告哪个字段被改动
+var wavHeaderFields = []struct {
+	offset int
+	name   string
+}{
+	{0, "ChunkID"}, {4, "ChunkSize"}, {8, "Format"},
+	{12, "Subchunk1ID"}, {16, "Subchunk1Size"}, {20, "AudioFormat"}, {22, "NumChannels"},
+	{24, "SampleRate"}, {28, "ByteRate"}, {32, "BlockAlign"}, {34, "BitsPerSample"},
+	{36, "Subchunk2ID"}, {40, "Subchunk2Size"},
+}
+
+// WAV 是 volume 处理的 16 位 PCM 音频：44 字节头之后全部视为样本
+type WAV struct {
+	Header  []byte
+	Samples []int16
+}
+
+// ParseWAV 解析 WAV 文件
+func ParseWAV(data []byte) (*WAV, error) {
+	if len(data) < WAVHeaderSize {
+		return nil, fmt.Errorf("file is too short to be a WAV (%d bytes)", len(data))
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a WAV file (missing RIFF/WAVE signature)")
+	}
+	body := data[WAVHeaderSize:]
+	if len(body)%2 != 0 {
+		return nil, fmt.Errorf("sample data has an odd number of bytes (%d)", len(body))
+	}
+	w := &WAV{Header: data[:WAVHeaderSize], Samples: make([]int16, len(body)/2)}
+	for i := range w.Samples {
+		w.Samples[i] = int16(binary.LittleEndian.Uint16(body[i*2:]))
+	}
+	return w, nil
+}
+
+// EncodeWAV 生成单声道 16 位 PCM WAV
+func EncodeWAV(samples []int16, sampleRate int) []byte {
+	le := binary.LittleEndian
+	data := make([]byte, WAVHeaderSize+len(samples)*2)
+	copy(data[0:4], "RIFF")
+	le.PutUint32(data[4:8], uint32(len(data)-8))
+	copy(data[8:12], "WAVE")
+	copy(data[12:16], "fmt ")
+	le.PutUint32(data[16:20], 16)
+	le.PutUint16(data[20:22], 1) // PCM
+	le.PutUint16(data[22:24], 1) // 单声道
+	le.PutUint32(data[24:28], uint32(sampleRate))
+	le.PutUint32(data[28:32], uint32(sampleRate*2))
+	le.PutUint16(data[32:34], 2)
+	le.PutUint16(data[34:36], 16)
+	copy(data[36:40], "data")
+	le.PutUint32(data[40:44], uint32(len(samples)*2))
+	for i, s := range samples {
+		le.PutUint16(data[WAVHeaderSize+i*2:], uint16(s))
+	}
+	return data
+}
+
+// wavHeaderField 返回偏移所在的头字段名
+func wavHeaderField(offset int) string {
+	name := wavHeaderFields[0].name
+	for _, f := range wavHeaderFields {
+		if f.offset <= offset {
+			name = f.name
+		}
+	}
+	return name
+}
+
+// CheckScaledWAV 检查 output 是否为 input 的每个样本乘以 factor 的结果：
+// 头必须原样复制；样本允许截断或四舍五入。骨架代码用 float 保存 factor，
+// 因此 float32 与 float64 两种精度下的乘积都接受
+func CheckScaledWAV(input, output *WAV, factor float64) error {
+	if !bytes.Equal(input.Header, output.Header) {
+		for i := range input.Header {
+			if input.Header[i] != output.Header[i] {
+				return fmt.Errorf("header was not copied unchanged: byte %d (%s) should be 0x%02x, got 0x%02x",
+					i, wavHeaderField(i), input.Header[i], output.Header[i])
+			}
+		}
+	}
+
+	if len(output.Samples) != len(input.Samples) {
+		return fmt.Errorf("output has %d samples, expected %d", len(output.Samples), len(input.Samples))
+	}
+
+	for i, s := range input.Samples {
+		exact := float64(s) * factor
+		single := float64(float32(s) * float32(factor))
+		got := output.Samples[i]
+		if acceptsScaledSample(got, exact) || acceptsScaledSample(got, single) {
+			continue
+		}
+		return fmt.Errorf("sample %d (byte %d) is wrong: %d × %g = %g, expected %d (truncated) or %d (rounded), got %d",
+			i, WAVHeaderSize+i*2, s, factor, exact, int16(math.Trunc(exact)), int16(math.Round(exact)), got)
+	}
+	return nil
+}
+
+// acceptsScaledSample 判断 got 是否为 product 截断或四舍五入后的值
+func acceptsScaledSample(got int16, product float64) bool {
+	return float64(got) == math.Trunc(product) || float64(got) == math.Round(product)
+}
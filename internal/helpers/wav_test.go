@@ -0,0 +1,55 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWAVRoundTrip(t *testing.T) {
+	samples := []int16{0, 1, -1, 32767, -32768, 1234}
+	w, err := ParseWAV(EncodeWAV(samples, 44100))
+	require.NoError(t, err)
+	assert.Equal(t, samples, w.Samples)
+	assert.Len(t, w.Header, WAVHeaderSize)
+
+	_, err = ParseWAV([]byte("definitely not a wav file, just some text here..."))
+	assert.ErrorContains(t, err, "RIFF")
+}
+
+func TestCheckScaledWAV(t *testing.T) {
+	input, err := ParseWAV(EncodeWAV([]int16{100, -101, 7, 0}, 8000))
+	require.NoError(t, err)
+
+	scaled := func(samples ...int16) *WAV {
+		w, err := ParseWAV(EncodeWAV(samples, 8000))
+		require.NoError(t, err)
+		return w
+	}
+
+	// 截断与四舍五入都接受
+	assert.NoError(t, CheckScaledWAV(input, scaled(50, -50, 3, 0), 0.5))
+	assert.NoError(t, CheckScaledWAV(input, scaled(50, -51, 4, 0), 0.5))
+
+	err = CheckScaledWAV(input, scaled(50, -50, 2, 0), 0.5)
+	assert.ErrorContains(t, err, "sample 2 (byte 48) is wrong: 7 × 0.5 = 3.5, expected 3 (truncated) or 4 (rounded), got 2")
+
+	truncated := scaled(50, -50, 3, 0)
+	truncated.Samples = truncated.Samples[:2]
+	err = CheckScaledWAV(input, truncated, 0.5)
+	assert.ErrorContains(t, err, "output has 2 samples, expected 4")
+
+	// 骨架用 float 保存 factor：float32 乘积 -3899.9998 截断为 -3899，
+	// 而 float64 的 -3900.0000000000005 截断为 -3900，两者都应接受
+	loud, err := ParseWAV(EncodeWAV([]int16{-30000}, 8000))
+	require.NoError(t, err)
+	assert.NoError(t, CheckScaledWAV(loud, scaled(-3899), 0.13))
+	assert.NoError(t, CheckScaledWAV(loud, scaled(-3900), 0.13))
+	assert.Error(t, CheckScaledWAV(loud, scaled(-3898), 0.13))
+
+	badHeader := scaled(50, -50, 3, 0)
+	badHeader.Header = append([]byte(nil), badHeader.Header...)
+	badHeader.Header[24] = 0
+	assert.ErrorContains(t, CheckScaledWAV(input, badHeader, 0.5), "byte 24 (SampleRate)")
+}
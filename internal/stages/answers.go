@@ -0,0 +1,37 @@
+package stages
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+)
+
+// checkAnswersFile 按模板解析 answers.txt，逐条列出不合格的答案。
+// 文件中一个问题都找不到时（学生删掉了模板中的问题），退回到把整个文件当作一个答案检查，
+// 要求的词数为各问题之和
+func checkAnswersFile(content string, questions []helpers.AnswerQuestion) error {
+	answers := helpers.ParseAnswers(content, questions)
+	if !slices.ContainsFunc(answers, func(a helpers.Answer) bool { return a.Found }) {
+		answers = []helpers.Answer{wholeFileAnswer(content, questions)}
+	}
+	problems := helpers.CheckAnswers(answers)
+	if len(problems) == 0 {
+		return nil
+	}
+	return fmt.Errorf("answers.txt has %d incomplete answer(s):\n  %s", len(problems), strings.Join(problems, "\n  "))
+}
+
+// wholeFileAnswer 把整个文件作为对所有问题的一个回答
+func wholeFileAnswer(content string, questions []helpers.AnswerQuestion) helpers.Answer {
+	minWords := 0
+	for _, q := range questions {
+		minWords += q.MinWords
+	}
+	return helpers.Answer{
+		Question: helpers.AnswerQuestion{Label: "answers.txt", MinWords: minWords},
+		Found:    true,
+		Text:     strings.TrimSpace(content),
+	}
+}
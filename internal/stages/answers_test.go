@@ -0,0 +1,67 @@
+package stages
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckAnswersFile(t *testing.T) {
+	reflection := strings.Repeat("the energy and valence suggest an upbeat listener ", 5)
+
+	tests := []struct {
+		name    string
+		content string
+		// wantErr 为空表示应通过
+		wantErr string
+	}{
+		{
+			name: "songs template answered",
+			content: songsQuestions[0].Text + "\n\n" + reflection + "\n\n" +
+				songsQuestions[1].Text + "\n\n" + reflection + "only one year of data\n",
+		},
+		{
+			name:    "songs prompts removed, whole file long enough",
+			content: reflection + "\n\n" + reflection + "\n",
+		},
+		{
+			name:    "songs prompts removed, whole file too short",
+			content: "upbeat and energetic\n",
+			wantErr: "answers.txt: has only 3 word(s); write at least 40",
+		},
+		{
+			name:    "songs prompts removed, placeholder left",
+			content: "TODO\n",
+			wantErr: "answers.txt: still contains the template placeholder TODO",
+		},
+		{
+			name:    "songs one prompt removed",
+			content: songsQuestions[0].Text + "\n\n" + reflection + "\n",
+			wantErr: `representativeness: question "Hypothesize about why`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkAnswersFile(tt.content, songsQuestions)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckSortAnswersShortExplanation(t *testing.T) {
+	content := "sort1 uses: Bubble\n\nHow do you know?: Fastest on sorted input.\n\n" +
+		"sort2 uses: Merge\n\nHow do you know?: Fastest on random input.\n\n" +
+		"sort3 uses: Selection\n\nHow do you know?: Same time on sorted input.\n"
+	assert.NoError(t, checkAnswersFile(content, sortQuestions))
+
+	content = strings.Replace(content, "Same time on sorted input.", "TODO", 1)
+	assert.ErrorContains(t, checkAnswersFile(content, sortQuestions), "sort3: How do you know?: still contains the template placeholder TODO")
+}
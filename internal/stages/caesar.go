@@ -2,6 +2,7 @@ package stages
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bootllm/llm100x-tester/internal/helpers"
@@ -13,7 +14,7 @@ import (
 func caesarTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "caesar",
-		Timeout:  30 * time.Second,
+		Timeout:  helpers.FuzzTimeout(30 * time.Second),
 		TestFunc: testCaesar,
 	}
 }
@@ -119,6 +120,23 @@ func testCaesar(harness *test_case_harness.TestCaseHarness) error {
 		logger.Successf("✓ %s", tc.name)
 	}
 
+	// 5. 可选：模糊测试 (BOOTLLM_FUZZ=1，默认关闭)
+	if helpers.FuzzEnabled() {
+		if err := runFuzzPass(harness, "caesar.c", caesarFuzzInputs()); err != nil {
+			return err
+		}
+	}
+
 	logger.Successf("All caesar tests passed!")
 	return nil
 }
+
+// caesarFuzzInputs 生成 caesar 的模糊测试输入：不友好的 key 以及不友好的明文
+func caesarFuzzInputs() []helpers.FuzzInput {
+	var inputs []helpers.FuzzInput
+	keys := append([]string{strings.Repeat("9", 10000), "１２", "\xff"}, helpers.HostileNumbers...)
+	for _, key := range keys {
+		inputs = append(inputs, helpers.FuzzInput{Args: []string{key}, Stdin: "hello, world\n"})
+	}
+	return append(inputs, textFuzzInputs("13")...)
+}
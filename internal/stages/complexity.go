@@ -0,0 +1,201 @@
+package stages
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/random"
+)
+
+// timeCommand 运行一次命令并返回耗时，stdinPath 为空时不提供输入
+// 超过 budget 时终止命令，返回已用时间与 helpers.ErrBudgetExceeded
+func timeCommand(budget time.Duration, workDir, stdinPath, name string, args ...string) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), budget)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = workDir
+	cmd.Stdout = io.Discard
+	if stdinPath != "" {
+		f, err := os.Open(stdinPath)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		cmd.Stdin = f
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	elapsed := time.Since(start)
+	if ctx.Err() == context.DeadlineExceeded {
+		return elapsed, helpers.ErrBudgetExceeded
+	}
+	if err != nil {
+		return 0, fmt.Errorf("%v\n%s", err, stderr.String())
+	}
+	return elapsed, nil
+}
+
+// reportScaling 记录拟合结果，超出期望复杂度时返回错误
+func reportScaling(logger *logger.Logger, what string, fit helpers.ComplexityFit, err error) error {
+	if err != nil {
+		return fmt.Errorf("%s: %v", what, err)
+	}
+	logger.Successf("✓ %s scales acceptably (%s)", what, fit)
+	return nil
+}
+
+// checkSpellerScaling 用随机生成的字典和文本测量 speller 的增长阶
+// 固定桶数的哈希表在规模增大时会退化为 O(n²)
+func checkSpellerScaling(logger *logger.Logger, workDir string) error {
+	tmpDir, err := os.MkdirTemp("", "speller-scaling-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sizes := []int{25000, 50000, 100000, 200000}
+	for _, n := range sizes {
+		dict := randomDictionary(n)
+		text := randomText(dict, n)
+		if err := os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("dict-%d", n)), []byte(strings.Join(dict, "\n")+"\n"), 0644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("text-%d", n)), []byte(text), 0644); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Measuring speller on dictionaries of %v words...", sizes)
+	fit, err := helpers.CheckScaling(sizes, helpers.Linear, func(n int, budget time.Duration) (time.Duration, error) {
+		return timeCommand(budget, workDir, "", "./speller",
+			filepath.Join(tmpDir, fmt.Sprintf("dict-%d", n)),
+			filepath.Join(tmpDir, fmt.Sprintf("text-%d", n)))
+	})
+	return reportScaling(logger, "speller", fit, err)
+}
+
+// checkDnaScaling 用越来越长的序列测量 dna.py 的增长阶
+// STR 连续重复次数有上限，因此正确的 longest_match 应为线性
+func checkDnaScaling(logger *logger.Logger, workDir string) error {
+	tmpDir, err := os.MkdirTemp("", "dna-scaling-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	strs := []string{"AGATC", "AATG", "TATC"}
+	database := "name," + strings.Join(strs, ",") + "\nAlice,2,8,3\nBob,4,1,5\n"
+	databasePath := filepath.Join(tmpDir, "database.csv")
+	if err := os.WriteFile(databasePath, []byte(database), 0644); err != nil {
+		return err
+	}
+
+	sizes := []int{20000, 40000, 80000, 160000}
+	for _, n := range sizes {
+		seq := helpers.RandomDNASequence(n, strs, 8)
+		if err := os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("seq-%d.txt", n)), []byte(seq), 0644); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Measuring dna.py on sequences of %v bases...", sizes)
+	fit, err := helpers.CheckScaling(sizes, helpers.Linear, func(n int, budget time.Duration) (time.Duration, error) {
+		return timeCommand(budget, workDir, "", "python3", "dna.py", databasePath,
+			filepath.Join(tmpDir, fmt.Sprintf("seq-%d.txt", n)))
+	})
+	return reportScaling(logger, "dna.py", fit, err)
+}
+
+// checkTidemanScaling 用越来越多的选民测量 tideman 的增长阶
+// 候选人上限固定为 9，每张选票的处理代价恒定，因此应对选民数线性
+func checkTidemanScaling(logger *logger.Logger, workDir string) error {
+	tmpDir, err := os.MkdirTemp("", "tideman-scaling-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	candidates := []string{"Alice", "Bob", "Charlie", "David", "Erin", "Frank", "Grace", "Heidi", "Ivan"}
+	sizes := []int{2000, 4000, 8000, 16000}
+	for _, n := range sizes {
+		var b strings.Builder
+		fmt.Fprintf(&b, "%d\n", n)
+		for v := 0; v < n; v++ {
+			for _, name := range random.ShuffleArray(append([]string(nil), candidates...)) {
+				b.WriteString(name + "\n")
+			}
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, fmt.Sprintf("votes-%d", n)), []byte(b.String()), 0644); err != nil {
+			return err
+		}
+	}
+
+	logger.Infof("Measuring tideman with %v voters...", sizes)
+	fit, err := helpers.CheckScaling(sizes, helpers.Linear, func(n int, budget time.Duration) (time.Duration, error) {
+		return timeCommand(budget, workDir, filepath.Join(tmpDir, fmt.Sprintf("votes-%d", n)), "./tideman", candidates...)
+	})
+	return reportScaling(logger, "tideman", fit, err)
+}
+
+// randomWord 生成长度在 [minLen, maxLen] 之间的随机小写单词
+func randomWord(minLen, maxLen int) string {
+	n := random.RandomInt(minLen, maxLen+1)
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte('a' + random.RandomInt(0, 26))
+	}
+	return string(b)
+}
+
+// randomDictionary 生成 n 个互不相同、按字母排序的随机单词
+// 部分单词带有 's，以覆盖撇号的处理
+func randomDictionary(n int) []string {
+	seen := make(map[string]bool, n)
+	words := make([]string, 0, n)
+	for len(words) < n {
+		w := randomWord(2, 12)
+		if random.RandomInt(0, 10) == 0 {
+			w += "'s"
+		}
+		if seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	sort.Strings(words)
+	return words
+}
+
+// randomText 生成包含 n 个单词的文本，约一半来自字典，其余为随机单词
+func randomText(dict []string, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		if random.RandomInt(0, 2) == 0 {
+			b.WriteString(dict[random.RandomInt(0, len(dict))])
+		} else {
+			b.WriteString(randomWord(1, 14))
+		}
+		if i%12 == 11 {
+			b.WriteString(".\n")
+		} else {
+			b.WriteString(" ")
+		}
+	}
+	return b.String()
+}
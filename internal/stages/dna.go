@@ -2,8 +2,12 @@ package stages
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/random"
 	"github.com/bootllm/tester-utils/runner"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
@@ -12,7 +16,7 @@ import (
 func dnaTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "dna",
-		Timeout:  60 * time.Second,
+		Timeout:  helpers.ComplexityTimeout(90 * time.Second),
 		TestFunc: testDna,
 	}
 }
@@ -69,12 +73,97 @@ func testDna(harness *test_case_harness.TestCaseHarness) error {
 			Exit(0)
 
 		if err := r.Error(); err != nil {
-			return fmt.Errorf("%s: %v", tc.name, err)
+			return fmt.Errorf("%s: %v", tc.name, helpers.ExplainPythonError(r, "dna.py", err))
 		}
 
 		logger.Successf("✓ %s", tc.name)
 	}
 
+	// 3. 随机生成的数据库与序列，与 Go 参考实现比对
+	logger.Infof("Testing generated databases and sequences...")
+	if err := checkGeneratedDna(workDir); err != nil {
+		return err
+	}
+	logger.Successf("✓ identifies generated sequences correctly")
+
+	// 可选：复杂度检查 (BOOTLLM_COMPLEXITY=1，默认关闭)
+	if helpers.ComplexityEnabled() {
+		if err := checkDnaScaling(logger, workDir); err != nil {
+			return err
+		}
+	}
+
 	logger.Successf("All tests passed!")
 	return nil
 }
+
+// checkGeneratedDna 生成若干数据库与序列运行 dna.py：
+// 完全匹配、与某人只差一个 STR 计数的近似匹配、随机档案，以及一条很长的序列
+// （平方复杂度的 longest_match 会在长序列上超时）
+func checkGeneratedDna(workDir string) error {
+	tmpDir, err := os.MkdirTemp("", "dna-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	cases := []struct {
+		name    string
+		length  int
+		timeout time.Duration
+		profile func(db *helpers.DNADatabase) []int
+	}{
+		{"matching sequence", 3000, 5 * time.Second, func(db *helpers.DNADatabase) []int {
+			return db.People[random.RandomInt(0, len(db.People))].Counts
+		}},
+		{"matching sequence", 3000, 5 * time.Second, func(db *helpers.DNADatabase) []int {
+			return db.People[random.RandomInt(0, len(db.People))].Counts
+		}},
+		{"near match (one STR count off by one)", 3000, 5 * time.Second, func(db *helpers.DNADatabase) []int {
+			counts := append([]int(nil), db.People[random.RandomInt(0, len(db.People))].Counts...)
+			i := random.RandomInt(0, len(counts))
+			if random.RandomInt(0, 2) == 0 {
+				counts[i]++
+			} else {
+				counts[i]--
+			}
+			return counts
+		}},
+		{"random profile", 3000, 5 * time.Second, func(db *helpers.DNADatabase) []int {
+			counts := make([]int, len(db.STRs))
+			for i := range counts {
+				counts[i] = random.RandomInt(0, 16)
+			}
+			return counts
+		}},
+		{"long sequence", 200000, 15 * time.Second, func(db *helpers.DNADatabase) []int {
+			return db.People[random.RandomInt(0, len(db.People))].Counts
+		}},
+	}
+
+	for i, tc := range cases {
+		db := helpers.GenerateDNADatabase(random.RandomInt(3, 9), random.RandomInt(3, 12))
+		sequence, _ := helpers.GenerateDNASequence(db, tc.profile(db), tc.length)
+		expected := db.Identify(sequence)
+
+		databasePath := filepath.Join(tmpDir, fmt.Sprintf("database-%d.csv", i))
+		sequencePath := filepath.Join(tmpDir, fmt.Sprintf("sequence-%d.txt", i))
+		if err := os.WriteFile(databasePath, []byte(db.CSV()), 0644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(sequencePath, []byte(sequence), 0644); err != nil {
+			return err
+		}
+
+		r := runner.Run(workDir, "python3", "dna.py", databasePath, sequencePath).
+			WithTimeout(tc.timeout).
+			Execute().
+			Stdout(expected).
+			Exit(0)
+		if err := r.Error(); err != nil {
+			return fmt.Errorf("generated %s (%d bases, STRs %v, expected %q): %v",
+				tc.name, len(sequence), db.STRs, expected, helpers.ExplainPythonError(r, "dna.py", err))
+		}
+	}
+	return nil
+}
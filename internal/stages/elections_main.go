@@ -0,0 +1,134 @@
+package stages
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/runner"
+)
+
+// electionMainCase 是对选举程序 main 的一次端到端运行
+type electionMainCase struct {
+	name   string
+	args   []string
+	inputs []string
+	exit   int
+	// output 是输出中必须出现的提示信息（为空时不检查）
+	output string
+	// winners 是 exit 为 0 时期望输出的胜者（去掉输入提示后的全部输出）
+	winners []string
+}
+
+// electionMainCases 返回 runoff / tideman 共同的端到端用例
+// invalidVoteExit 是发现无效选票时的退出码（runoff 为 4，tideman 为 3）
+func electionMainCases(program string, invalidVoteExit int) []electionMainCase {
+	names := []string{"Alice", "Bob", "Charlie", "David", "Erin", "Frank", "Grace", "Heidi", "Ivan", "Judy"}
+
+	// 9 名候选人、1 名选民，按逆序排名，胜者是 Ivan
+	maxInputs := []string{"1"}
+	for i := 8; i >= 0; i-- {
+		maxInputs = append(maxInputs, names[i])
+	}
+
+	return []electionMainCase{
+		{
+			name:   "rejects missing candidates with usage message",
+			exit:   1,
+			output: "Usage: " + program,
+		},
+		{
+			name:   "rejects more than 9 candidates",
+			args:   names,
+			exit:   2,
+			output: "Maximum number of candidates is 9",
+		},
+		{
+			name:    "accepts 9 candidates",
+			args:    names[:9],
+			inputs:  maxInputs,
+			winners: []string{"Ivan"},
+		},
+		{
+			name:   "exits on vote for invalid candidate name",
+			args:   []string{"Alice", "Bob"},
+			inputs: []string{"1", "Mallory"},
+			exit:   invalidVoteExit,
+			output: "Invalid vote.",
+		},
+		{
+			name:   "exits on invalid vote after valid ballots",
+			args:   []string{"Alice", "Bob", "Charlie"},
+			inputs: []string{"2", "Alice", "Bob", "Charlie", "Bob", "Mallory"},
+			exit:   invalidVoteExit,
+			output: "Invalid vote.",
+		},
+		{
+			name:    "prints only the winner's name",
+			args:    []string{"Alice", "Bob", "Charlie"},
+			inputs:  []string{"3", "Bob", "Alice", "Charlie", "Bob", "Charlie", "Alice", "Charlie", "Bob", "Alice"},
+			winners: []string{"Bob"},
+		},
+	}
+}
+
+// runoffMainCases 在共同用例之外检查 runoff 的选民上限
+func runoffMainCases() []electionMainCase {
+	return append(electionMainCases("runoff", 4), electionMainCase{
+		name:   "rejects more than 100 voters",
+		args:   []string{"Alice", "Bob"},
+		inputs: []string{"101"},
+		exit:   3,
+		output: "Maximum number of voters is 100",
+	})
+}
+
+// checkElectionMain 通过 pty 运行编译好的完整程序，检查参数校验、无效选票处理和胜者输出格式
+func checkElectionMain(logger *logger.Logger, workDir, program string, cases []electionMainCase) error {
+	for _, tc := range cases {
+		logger.Infof("Testing ./%s %s...", program, tc.name)
+
+		r := runner.Run(workDir, program, tc.args...).
+			WithTimeout(5 * time.Second).
+			WithPty().
+			Start()
+		for _, input := range tc.inputs {
+			r.SendLine(input)
+		}
+		r.WaitForExit().Exit(tc.exit)
+
+		invocation := strings.TrimSpace("./" + program + " " + strings.Join(tc.args, " "))
+		if err := r.Error(); err != nil {
+			return fmt.Errorf("%s %s: %v\nran %s with input: %s", program, tc.name, err, invocation, describeInputs(tc.inputs))
+		}
+
+		output := strings.ReplaceAll(r.GetStdout(), "\r", "")
+		if tc.output != "" && !strings.Contains(output, tc.output) {
+			return fmt.Errorf("%s %s: expected %q in output of %s, got %q", program, tc.name, tc.output, invocation, output)
+		}
+		if tc.winners != nil {
+			if actual := helpers.ParseElectionOutput(output); !winnersMatch(tc.winners, actual) {
+				return fmt.Errorf("%s %s: expected %s to print exactly %v (one name per line), got %q",
+					program, tc.name, invocation, tc.winners, output)
+			}
+		}
+
+		logger.Successf("✓ ./%s %s", program, tc.name)
+	}
+	return nil
+}
+
+// describeInputs 以逗号连接输入，便于在错误信息中复现
+func describeInputs(inputs []string) string {
+	if len(inputs) == 0 {
+		return "(none)"
+	}
+	quoted := make([]string, len(inputs))
+	for i, in := range inputs {
+		quoted[i] = strconv.Quote(in)
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,117 @@
+package stages
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/runner"
+)
+
+// randomElectionCount 是每个选举 stage 运行的随机选举数量
+const randomElectionCount = 5
+
+// electionKind 描述一种选举程序及其参考计票
+type electionKind struct {
+	program  string
+	generate func(rng *rand.Rand) helpers.Election
+	// winners 返回所有可接受的胜者名单
+	winners func(e helpers.Election) [][]string
+	// anyOf 为 true 时，程序只需输出 winners 中的任意一位（tideman 的同强度数对可以任意排序）
+	anyOf bool
+}
+
+var pluralityElections = electionKind{
+	program:  "plurality",
+	generate: helpers.GeneratePluralityElection,
+	winners:  func(e helpers.Election) [][]string { return [][]string{helpers.PluralityWinners(e)} },
+}
+
+var runoffElections = electionKind{
+	program:  "runoff",
+	generate: helpers.GenerateRunoffElection,
+	winners:  func(e helpers.Election) [][]string { return [][]string{helpers.RunoffWinners(e)} },
+}
+
+var tidemanElections = electionKind{
+	program:  "tideman",
+	generate: helpers.GenerateTidemanElection,
+	winners: func(e helpers.Election) [][]string {
+		winners, _ := helpers.RankedPairsWinners(e)
+		var options [][]string
+		for _, w := range winners {
+			options = append(options, []string{w})
+		}
+		return options
+	},
+	anyOf: true,
+}
+
+// checkRandomElections 用随机选票运行学生编译好的完整程序，与 Go 参考计票比对胜者
+// 失败时报告种子，设置 BOOTLLM_ELECTION_SEED 即可复现同一组选举
+func checkRandomElections(workDir string, kind electionKind) error {
+	seed := helpers.ElectionSeed()
+	rng := rand.New(rand.NewSource(seed))
+
+	for i := 0; i < randomElectionCount; i++ {
+		e := kind.generate(rng)
+		actual, err := runElection(workDir, kind.program, e)
+		if err == nil && !acceptableWinners(kind.winners(e), actual) {
+			err = fmt.Errorf("expected winner(s) %s, got %v", describeWinners(kind, e), actual)
+		}
+		if err != nil {
+			return fmt.Errorf("random election %d of %d failed: %v\ncandidates: %s\nballots:\n%s\nrerun with BOOTLLM_ELECTION_SEED=%d to reproduce",
+				i+1, randomElectionCount, err, strings.Join(e.Candidates, " "), formatBallots(e), seed)
+		}
+	}
+	return nil
+}
+
+// runElection 以候选人为参数启动程序，通过 pty 回答提示，返回输出的胜者
+func runElection(workDir, program string, e helpers.Election) ([]string, error) {
+	r := runner.Run(workDir, program, e.Candidates...).
+		WithTimeout(5 * time.Second).
+		WithPty().
+		Start()
+	for _, input := range e.Inputs() {
+		r.SendLine(input)
+	}
+	r.WaitForExit().Exit(0)
+	if err := r.Error(); err != nil {
+		return nil, err
+	}
+	return helpers.ParseElectionOutput(r.GetStdout()), nil
+}
+
+// acceptableWinners 判断实际输出是否是可接受的胜者名单之一
+func acceptableWinners(options [][]string, actual []string) bool {
+	for _, expected := range options {
+		if winnersMatch(expected, actual) {
+			return true
+		}
+	}
+	return false
+}
+
+// describeWinners 描述期望的胜者
+func describeWinners(kind electionKind, e helpers.Election) string {
+	var names []string
+	for _, option := range kind.winners(e) {
+		names = append(names, strings.Join(option, ", "))
+	}
+	if kind.anyOf && len(names) > 1 {
+		return "one of [" + strings.Join(names, ", ") + "]"
+	}
+	return "[" + strings.Join(names, ", ") + "]"
+}
+
+// formatBallots 每行一张选票
+func formatBallots(e helpers.Election) string {
+	lines := make([]string, len(e.Ballots))
+	for i, ballot := range e.Ballots {
+		lines[i] = "  " + strings.Join(ballot, " > ")
+	}
+	return strings.Join(lines, "\n")
+}
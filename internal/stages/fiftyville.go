@@ -8,10 +8,18 @@ import (
 	"strings"
 	"time"
 
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
 )
 
+// fiftyvilleEvidenceTables 是解开谜题必须查询的表
+var fiftyvilleEvidenceTables = []string{
+	"crime_scene_reports", "interviews", "atm_transactions", "phone_calls", "flights",
+}
+
 func fiftyvilleTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "fiftyville",
@@ -24,7 +32,14 @@ func testFiftyville(harness *test_case_harness.TestCaseHarness) error {
 	logger := harness.Logger
 	workDir := harness.SubmissionDir
 
-	// 1. 检查 log.sql 和 answers.txt 存在
+	// 1. 确定谜题：原版 fiftyville.db，或由 fiftyville.seed 生成的随机谜题
+	dbPath, answers, cleanupMystery, err := prepareFiftyville(logger, workDir)
+	if err != nil {
+		return err
+	}
+	defer cleanupMystery()
+
+	// 2. 检查 log.sql 和 answers.txt 存在
 	logger.Infof("Checking log.sql and answers.txt exist...")
 	if !harness.FileExists("log.sql") {
 		return fmt.Errorf("log.sql does not exist")
@@ -34,19 +49,40 @@ func testFiftyville(harness *test_case_harness.TestCaseHarness) error {
 	}
 	logger.Successf("log.sql and answers.txt exist")
 
-	// 2. 检查 log.sql 包含 SELECT 查询
-	logger.Infof("Checking log file contains SELECT queries...")
+	// 3. 在 fiftyville.db 的只读副本上重放 log.sql 中的每条查询
+	logger.Infof("Replaying log.sql against fiftyville.db...")
 	logContent, err := os.ReadFile(filepath.Join(workDir, "log.sql"))
 	if err != nil {
 		return fmt.Errorf("failed to read log.sql: %v", err)
 	}
-	logLower := strings.ToLower(string(logContent))
-	if !strings.Contains(logLower, "select") {
-		return fmt.Errorf("missing SELECT queries in log.sql")
+	db, cleanup, err := helpers.OpenSandboxedDB(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open fiftyville.db: %v", err)
+	}
+	defer cleanup()
+
+	report, err := helpers.ReplaySQLLog(db, string(logContent))
+	if err != nil {
+		return fmt.Errorf("failed to replay log.sql: %v", err)
+	}
+	failing := report.Failing()
+	logger.Infof("log.sql: %d queries, %d valid, %d failing (%d sqlite3 commands skipped)",
+		len(report.Statements), report.Valid(), len(failing), report.ShellCommands)
+	// 调查过程中写错查询很正常，失败的查询只给出警告
+	for _, s := range failing {
+		logger.Errorf("Warning: log.sql line %d: %v", s.Line, s.Err)
 	}
-	logger.Successf("log file contains SELECT queries")
+	if report.Valid() == 0 {
+		return fmt.Errorf("log.sql contains no valid SELECT queries")
+	}
+	logger.Infof("Tables investigated: %s", strings.Join(report.Tables(), ", "))
+	if missing := report.MissingTables(fiftyvilleEvidenceTables); len(missing) > 0 {
+		return fmt.Errorf("log.sql never queries %s; the investigation should follow the evidence in each of %s",
+			strings.Join(missing, ", "), strings.Join(fiftyvilleEvidenceTables, ", "))
+	}
+	logger.Successf("log.sql queries the key evidence tables")
 
-	// 3. 检查谜题是否解决
+	// 4. 检查谜题是否解决
 	logger.Infof("Checking mystery solved...")
 	answersContent, err := os.ReadFile(filepath.Join(workDir, "answers.txt"))
 	if err != nil {
@@ -54,14 +90,6 @@ func testFiftyville(harness *test_case_harness.TestCaseHarness) error {
 	}
 	answersLower := strings.ToLower(string(answersContent))
 
-	// 答案 (与 CS50 check50 对齐)
-	// thief: bruce (hex: 6272756365)
-	// city: new york (hex: 6e657720796f726b)
-	// accomplice: robin (hex: 726f62696e)
-	thief := "bruce"
-	city := "new york"
-	accomplice := "robin"
-
 	// 检查格式 - 每个关键词只能出现一次
 	for _, q := range []string{"thief is", "escaped to", "accomplice is"} {
 		if strings.Count(answersLower, q) > 1 {
@@ -70,9 +98,9 @@ func testFiftyville(harness *test_case_harness.TestCaseHarness) error {
 	}
 
 	// 使用正则匹配答案
-	thiefPattern := regexp.MustCompile(`thief\s*is\s*:?\s*` + regexp.QuoteMeta(thief))
-	cityPattern := regexp.MustCompile(`escaped\s*to\s*:?\s*` + regexp.QuoteMeta(city))
-	accomplicePattern := regexp.MustCompile(`accomplice\s*is\s*:?\s*` + regexp.QuoteMeta(accomplice))
+	thiefPattern := regexp.MustCompile(`thief\s*is\s*:?\s*` + regexp.QuoteMeta(answers.thief))
+	cityPattern := regexp.MustCompile(`escaped\s*to\s*:?\s*` + regexp.QuoteMeta(answers.city))
+	accomplicePattern := regexp.MustCompile(`accomplice\s*is\s*:?\s*` + regexp.QuoteMeta(answers.accomplice))
 
 	if !thiefPattern.MatchString(answersLower) {
 		return fmt.Errorf("answers.txt does not correctly identify the thief")
@@ -0,0 +1,94 @@
+package stages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// fiftyvilleAnswers 是谜题的答案（小写）
+type fiftyvilleAnswers struct {
+	thief      string
+	city       string
+	accomplice string
+}
+
+// cs50FiftyvilleAnswers 是 CS50 原版 fiftyville.db 的答案 (与 CS50 check50 对齐)
+// thief: bruce (hex: 6272756365)
+// city: new york (hex: 6e657720796f726b)
+// accomplice: robin (hex: 726f62696e)
+var cs50FiftyvilleAnswers = fiftyvilleAnswers{thief: "bruce", city: "new york", accomplice: "robin"}
+
+// prepareFiftyville 确定要调查的数据库及其答案：
+//   - 设置了 BOOTLLM_FIFTYVILLE_KEY 时，种子由 BOOTLLM_STUDENT 与密钥派生；
+//     提交目录中的 fiftyville.seed 必须存在且与之一致，然后在重新生成的数据库上重放 log.sql，答案也由种子得出
+//   - 没有密钥时使用 CS50 原版 fiftyville.db；此时出现 fiftyville.seed 说明谜题已随机化，但无法验证，直接拒绝
+//
+// 提交目录只读不写：发放的谜题数据库写在 testerCacheDir 中
+func prepareFiftyville(logger *logger.Logger, workDir string) (dbPath string, answers fiftyvilleAnswers, cleanup func(), err error) {
+	studentSeed, hasSeed, err := helpers.ReadFiftyvilleSeed(workDir)
+	if err != nil {
+		return "", answers, nil, err
+	}
+
+	key := helpers.FiftyvilleKey()
+	if key == "" {
+		if hasSeed {
+			return "", answers, nil, fmt.Errorf("found %s, but BOOTLLM_FIFTYVILLE_KEY is not set, so the randomized mystery cannot be verified",
+				helpers.FiftyvilleSeedFile)
+		}
+		studentDB := filepath.Join(workDir, "fiftyville.db")
+		if _, err := os.Stat(studentDB); err != nil {
+			return "", answers, nil, fmt.Errorf("fiftyville.db does not exist")
+		}
+		return studentDB, cs50FiftyvilleAnswers, func() {}, nil
+	}
+
+	student := os.Getenv("BOOTLLM_STUDENT")
+	if student == "" {
+		return "", answers, nil, fmt.Errorf("BOOTLLM_FIFTYVILLE_KEY is set but BOOTLLM_STUDENT is not; the mystery is tied to the student")
+	}
+	seed := helpers.FiftyvilleSeed(key, student)
+	mystery := helpers.GenerateFiftyville(seed)
+
+	if !hasSeed {
+		// 发放谜题：写到提交目录之外，由学生自己把种子保存到 fiftyville.seed
+		dir, err := testerCacheDir()
+		if err != nil {
+			return "", answers, nil, err
+		}
+		issued := filepath.Join(dir, fmt.Sprintf("fiftyville-%d.db", seed))
+		if err := mystery.WriteDB(issued); err != nil {
+			return "", answers, nil, fmt.Errorf("failed to generate your Fiftyville mystery: %v", err)
+		}
+		return "", answers, nil, fmt.Errorf("%s is missing; your Fiftyville mystery for %s is in %s. "+
+			"Investigate that database, then write %d to %s next to log.sql and answers.txt",
+			helpers.FiftyvilleSeedFile, student, issued, seed, helpers.FiftyvilleSeedFile)
+	}
+	if studentSeed != seed {
+		return "", answers, nil, fmt.Errorf("%s does not match the mystery issued to %s; solve your own mystery",
+			helpers.FiftyvilleSeedFile, student)
+	}
+
+	// 在重新生成的数据库上重放，学生对数据库的修改不影响结果
+	logger.Infof("Using the randomized Fiftyville mystery issued to %s (seed %d)", student, seed)
+	dir, err := os.MkdirTemp("", "fiftyville-")
+	if err != nil {
+		return "", answers, nil, err
+	}
+	dbPath = filepath.Join(dir, "fiftyville.db")
+	if err := mystery.WriteDB(dbPath); err != nil {
+		os.RemoveAll(dir)
+		return "", answers, nil, fmt.Errorf("failed to regenerate fiftyville.db: %v", err)
+	}
+	answers = fiftyvilleAnswers{
+		thief:      strings.ToLower(mystery.Thief),
+		city:       strings.ToLower(mystery.City),
+		accomplice: strings.ToLower(mystery.Accomplice),
+	}
+	return dbPath, answers, func() { os.RemoveAll(dir) }, nil
+}
@@ -0,0 +1,99 @@
+package stages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// listDir 返回目录中的文件名，用于确认提交目录没有被改动
+func listDir(t *testing.T, dir string) []string {
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestPrepareFiftyville(t *testing.T) {
+	l := logger.GetLogger(false, "[test] ")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	writeSeed := func(dir string, seed int64) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, helpers.FiftyvilleSeedFile), []byte(fmt.Sprintln(seed)), 0644))
+	}
+
+	t.Run("original database without a key", func(t *testing.T) {
+		t.Setenv("BOOTLLM_FIFTYVILLE_KEY", "")
+		workDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, "fiftyville.db"), nil, 0644))
+		dbPath, answers, cleanup, err := prepareFiftyville(l, workDir)
+		require.NoError(t, err)
+		defer cleanup()
+		assert.Equal(t, filepath.Join(workDir, "fiftyville.db"), dbPath)
+		assert.Equal(t, cs50FiftyvilleAnswers, answers)
+	})
+
+	t.Run("seed without a key cannot be verified", func(t *testing.T) {
+		t.Setenv("BOOTLLM_FIFTYVILLE_KEY", "")
+		workDir := t.TempDir()
+		writeSeed(workDir, 42)
+		_, _, _, err := prepareFiftyville(l, workDir)
+		assert.ErrorContains(t, err, "cannot be verified")
+	})
+
+	t.Run("key requires a student", func(t *testing.T) {
+		t.Setenv("BOOTLLM_FIFTYVILLE_KEY", "grader-key")
+		t.Setenv("BOOTLLM_STUDENT", "")
+		_, _, _, err := prepareFiftyville(l, t.TempDir())
+		assert.ErrorContains(t, err, "BOOTLLM_STUDENT is not")
+	})
+
+	t.Setenv("BOOTLLM_FIFTYVILLE_KEY", "grader-key")
+	t.Setenv("BOOTLLM_STUDENT", "alice")
+	seed := helpers.FiftyvilleSeed("grader-key", "alice")
+
+	t.Run("missing seed issues the mystery outside the submission", func(t *testing.T) {
+		workDir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, "fiftyville.db"), nil, 0644))
+		_, _, _, err := prepareFiftyville(l, workDir)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("write %d to fiftyville.seed", seed))
+		assert.Equal(t, []string{"fiftyville.db"}, listDir(t, workDir))
+
+		issued := filepath.Join(os.Getenv("XDG_CACHE_HOME"), "llm100x-tester", fmt.Sprintf("fiftyville-%d.db", seed))
+		assert.FileExists(t, issued)
+	})
+
+	t.Run("seed of another student is rejected", func(t *testing.T) {
+		workDir := t.TempDir()
+		writeSeed(workDir, helpers.FiftyvilleSeed("grader-key", "bob"))
+		_, _, _, err := prepareFiftyville(l, workDir)
+		assert.ErrorContains(t, err, "does not match the mystery issued to alice")
+	})
+
+	t.Run("matching seed regenerates the mystery", func(t *testing.T) {
+		workDir := t.TempDir()
+		writeSeed(workDir, seed)
+		dbPath, answers, cleanup, err := prepareFiftyville(l, workDir)
+		require.NoError(t, err)
+		defer cleanup()
+		assert.FileExists(t, dbPath)
+		assert.False(t, strings.HasPrefix(dbPath, workDir))
+
+		mystery := helpers.GenerateFiftyville(seed)
+		assert.Equal(t, strings.ToLower(mystery.Thief), answers.thief)
+		assert.Equal(t, strings.ToLower(mystery.City), answers.city)
+		assert.Equal(t, strings.ToLower(mystery.Accomplice), answers.accomplice)
+		assert.Equal(t, []string{helpers.FiftyvilleSeedFile}, listDir(t, workDir))
+	})
+}
@@ -0,0 +1,70 @@
+package stages
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+)
+
+// filterDiffPrefix 是写入提交目录的对比图文件名前缀
+const filterDiffPrefix = "filter-diff-"
+
+// filterFunctionNames 是 testing.c 的函数编号对应的滤镜名
+var filterFunctionNames = []string{"grayscale", "sepia", "reflect", "blur", "edges"}
+
+// removeFilterDiffs 删除上一次运行留下的对比图
+func removeFilterDiffs(workDir string) {
+	matches, _ := filepath.Glob(filepath.Join(workDir, filterDiffPrefix+"*.bmp"))
+	for _, m := range matches {
+		os.Remove(m)
+	}
+}
+
+// explainFilterDiff 写出 "期望 | 实际 | 差异" 对比图，返回错误模式的总结与图片路径
+func explainFilterDiff(workDir, label string, expected, actual *helpers.Image, tolerance int) string {
+	var b strings.Builder
+	if summary := helpers.SummarizeImageDifferences(expected, actual, tolerance); summary != "" {
+		fmt.Fprintf(&b, "\nPattern: %s", summary)
+	}
+	path := filepath.Join(workDir, filterDiffPrefix+label+".bmp")
+	if err := helpers.WriteBMP(path, helpers.DiffImage(expected, actual, tolerance)); err == nil {
+		fmt.Fprintf(&b, "\nVisual diff (expected | actual | difference): %s", path)
+	}
+	return b.String()
+}
+
+// parseFilterTriples 把 testing.c 输出的 "r g b" 行还原为图像
+// 像素数为平方数时视为正方形，否则视为单行（如 reflect 的 1x2、1x3 测试）
+func parseFilterTriples(s string) (*helpers.Image, bool) {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	n := len(lines)
+	width := int(math.Sqrt(float64(n)))
+	if width*width != n {
+		width = n
+	}
+	img := helpers.NewImage(width, n/width)
+	for k, line := range lines {
+		var p helpers.Pixel
+		if _, err := fmt.Sscanf(line, "%d %d %d", &p.R, &p.G, &p.B); err != nil {
+			return nil, false
+		}
+		img.Pixels[k/width][k%width] = p
+	}
+	return img, true
+}
+
+// filterMismatchError 报告 testing.c 输出不一致；两边都能解析为同样大小的图像时附上对比图
+func filterMismatchError(workDir string, function, test int, expected, actual string) error {
+	msg := fmt.Sprintf("output mismatch\nExpected:\n%s\nGot:\n%s", expected, actual)
+	e, ok1 := parseFilterTriples(expected)
+	a, ok2 := parseFilterTriples(actual)
+	if ok1 && ok2 && e.Width == a.Width && e.Height == a.Height {
+		label := fmt.Sprintf("%s-test%d", filterFunctionNames[function], test)
+		msg += explainFilterDiff(workDir, label, e, a, 0)
+	}
+	return fmt.Errorf("%s", msg)
+}
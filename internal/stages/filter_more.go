@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
 )
@@ -23,6 +24,7 @@ func filterMoreTestCase() tester_definition.TestCase {
 func testFilterMore(harness *test_case_harness.TestCaseHarness) error {
 	logger := harness.Logger
 	workDir := harness.SubmissionDir
+	removeFilterDiffs(workDir)
 
 	// 1. 检查 helpers.c 文件存在
 	logger.Infof("Checking helpers.c exists...")
@@ -40,14 +42,16 @@ func testFilterMore(harness *test_case_harness.TestCaseHarness) error {
 
 	// 3. 编译 filter
 	logger.Infof("Compiling filter...")
-	cmd := exec.Command("clang",
+	flags := []string{
 		"-ggdb3", "-gdwarf-4", "-O0", "-Qunused-arguments",
 		"-std=c11", "-Wall", "-Werror", "-Wextra",
 		"-Wno-gnu-folding-constant", "-Wno-sign-compare", "-Wno-unused-parameter", "-Wno-unused-variable",
-		"-Wshadow", "-lm", "-o", "testing", "testing.c", "helpers.c")
+		"-Wshadow",
+	}
+	cmd := exec.Command("clang", append(flags, "-lm", "-o", "testing", "testing.c", "helpers.c")...)
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("filter does not compile: %s\n%s", err, string(out))
+		return fmt.Errorf("filter does not compile: %s\n%s", err, helpers.ExplainClangOutput(workDir, string(out)))
 	}
 	logger.Successf("filter compiles")
 
@@ -167,8 +171,21 @@ func testFilterMore(harness *test_case_harness.TestCaseHarness) error {
 		logger.Successf("✓ %s", tc.name)
 	}
 
+	// 8. 在随机图像上运行 ./filter，与 Go 参考实现逐像素比较
+	if harness.FileExists("filter.c") {
+		if err := compileFilter(workDir, flags); err != nil {
+			return err
+		}
+		if err := checkRandomFilterImages(logger, workDir, []filterSpec{grayscaleFilter, reflectFilter, blurFilter, edgesFilter}); err != nil {
+			return err
+		}
+	} else {
+		logger.Infof("filter.c not found, skipping random image tests")
+	}
+
 	// 清理编译产物
 	os.Remove(filepath.Join(workDir, "testing"))
+	os.Remove(filepath.Join(workDir, "filter"))
 
 	logger.Successf("All filter-more tests passed!")
 	return nil
@@ -184,7 +201,7 @@ func runFilterMoreTest(workDir string, function, test int, expected string) erro
 
 	actual := string(out)
 	if actual != expected {
-		return fmt.Errorf("output mismatch\nExpected:\n%s\nGot:\n%s", expected, actual)
+		return filterMismatchError(workDir, function, test, expected, actual)
 	}
 
 	return nil
@@ -0,0 +1,123 @@
+package stages
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/random"
+)
+
+// filterSpec 描述 ./filter 的一个滤镜及其参考实现
+type filterSpec struct {
+	name      string
+	flag      string
+	reference func(*helpers.Image) *helpers.Image
+	// tolerance 是每个通道允许的误差：sepia 与 edges 涉及浮点系数和开方，
+	// 学生用 float 还是 double 可能让 .5 附近的结果相差 1
+	tolerance int
+}
+
+var (
+	grayscaleFilter = filterSpec{"grayscale", "-g", helpers.Grayscale, 0}
+	sepiaFilter     = filterSpec{"sepia", "-s", helpers.Sepia, 1}
+	reflectFilter   = filterSpec{"reflect", "-r", helpers.Reflect, 0}
+	blurFilter      = filterSpec{"blur", "-b", helpers.Blur, 0}
+	edgesFilter     = filterSpec{"edges", "-e", helpers.Edges, 1}
+)
+
+// compileFilter 编译 filter.c 与 helpers.c，flags 与编译 testing.c 时相同
+func compileFilter(workDir string, flags []string) error {
+	args := append(append([]string{}, flags...), "-lm", "-o", "filter", "filter.c", "helpers.c")
+	cmd := exec.Command("clang", args...)
+	cmd.Dir = workDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("filter does not compile: %s\n%s", err, helpers.ExplainClangOutput(workDir, string(out)))
+	}
+	return nil
+}
+
+// randomFilterImage 生成随机像素的图像
+func randomFilterImage(width, height int) *helpers.Image {
+	img := helpers.NewImage(width, height)
+	for i := range img.Pixels {
+		for j := range img.Pixels[i] {
+			img.Pixels[i][j] = helpers.Pixel{
+				R: uint8(random.RandomInt(0, 256)),
+				G: uint8(random.RandomInt(0, 256)),
+				B: uint8(random.RandomInt(0, 256)),
+			}
+		}
+	}
+	return img
+}
+
+// randomFilterSizes 返回测试用的图像尺寸：单行、单列，以及几个需要行填充的奇数尺寸
+func randomFilterSizes() [][2]int {
+	sizes := [][2]int{{1, 1}, {7, 1}, {1, 7}}
+	for i := 0; i < 3; i++ {
+		sizes = append(sizes, [2]int{random.RandomInt(1, 20)*2 + 1, random.RandomInt(1, 20)*2 + 1})
+	}
+	return sizes
+}
+
+// checkRandomFilterImages 在随机图像上运行学生的 ./filter，与参考实现逐像素比较
+func checkRandomFilterImages(logger *logger.Logger, workDir string, specs []filterSpec) error {
+	tmpDir, err := os.MkdirTemp("", "filter-random-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sizes := randomFilterSizes()
+	for _, spec := range specs {
+		logger.Infof("Testing %s on random images...", spec.name)
+		for _, size := range sizes {
+			img := randomFilterImage(size[0], size[1])
+			input := filepath.Join(tmpDir, "input.bmp")
+			output := filepath.Join(tmpDir, "output.bmp")
+			if err := helpers.WriteBMP(input, img); err != nil {
+				return err
+			}
+
+			cmd := exec.Command("./filter", spec.flag, input, output)
+			cmd.Dir = workDir
+			if out, err := cmd.CombinedOutput(); err != nil {
+				return fmt.Errorf("%s failed on a %dx%d image: %v\n%s", spec.name, img.Width, img.Height, helpers.ExplainCmdCrash(cmd, err), string(out))
+			}
+
+			actual, err := helpers.ReadBMP(output)
+			if err != nil {
+				return fmt.Errorf("%s produced an unreadable image for a %dx%d input: %v", spec.name, img.Width, img.Height, err)
+			}
+			expected := spec.reference(img)
+			diff, err := helpers.CompareImages(expected, actual, spec.tolerance)
+			if err != nil {
+				return fmt.Errorf("%s is incorrect on a %dx%d image: %v%s", spec.name, img.Width, img.Height, err, keepFilterInput(input))
+			}
+			if diff != nil {
+				label := fmt.Sprintf("%s-%dx%d", spec.name, img.Width, img.Height)
+				return fmt.Errorf("%s is incorrect on a %dx%d image: %s%s%s", spec.name, img.Width, img.Height, diff,
+					explainFilterDiff(workDir, label, expected, actual, spec.tolerance), keepFilterInput(input))
+			}
+		}
+		logger.Successf("✓ %s matches the reference on %d random images", spec.name, len(sizes))
+	}
+	return nil
+}
+
+// keepFilterInput 把失败的输入图像移到不会被清理的目录，返回提示文字
+func keepFilterInput(input string) string {
+	dir, err := os.MkdirTemp("", "filter-failing-")
+	if err != nil {
+		return ""
+	}
+	kept := filepath.Join(dir, "input.bmp")
+	if err := os.Rename(input, kept); err != nil {
+		return ""
+	}
+	return fmt.Sprintf("\nInput image saved to %s", kept)
+}
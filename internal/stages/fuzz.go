@@ -0,0 +1,48 @@
+package stages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/test_case_harness"
+)
+
+// runFuzzPass 用 sanitizer 重新编译学生程序，并用不友好的输入运行模糊测试
+// 只在 BOOTLLM_FUZZ=1 时由各 stage 调用
+func runFuzzPass(harness *test_case_harness.TestCaseHarness, source string, inputs []helpers.FuzzInput) error {
+	logger := harness.Logger
+	workDir := harness.SubmissionDir
+	binary := strings.TrimSuffix(source, ".c") + "_fuzz"
+
+	logger.Infof("Compiling %s with sanitizers for fuzzing...", source)
+	if err := helpers.CompileC(workDir, source, binary, true, helpers.SanitizerFlags...); err != nil {
+		return fmt.Errorf("%s does not compile with sanitizers: %v", source, err)
+	}
+	defer os.Remove(filepath.Join(workDir, binary))
+
+	logger.Infof("Fuzzing with %d hostile inputs...", len(inputs))
+	findings := helpers.NewFuzzer(workDir, binary).Run(inputs)
+	if len(findings) == 0 {
+		logger.Successf("✓ survives hostile inputs (no crashes, sanitizer reports or hangs)")
+		return nil
+	}
+
+	for _, f := range findings {
+		logger.Errorf("%s", f)
+	}
+	return fmt.Errorf("fuzzing found %d problem(s); first: %s", len(findings), findings[0])
+}
+
+// textFuzzInputs 将每段不友好的文本作为 stdin，参数固定为 args
+func textFuzzInputs(args ...string) []helpers.FuzzInput {
+	var inputs []helpers.FuzzInput
+	for _, text := range helpers.HostileTexts() {
+		inputs = append(inputs, helpers.FuzzInput{Args: args, Stdin: text + "\n"})
+	}
+	// 不带换行直接 EOF
+	inputs = append(inputs, helpers.FuzzInput{Args: args, Stdin: ""})
+	return inputs
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
 )
@@ -41,7 +42,7 @@ func testInheritance(harness *test_case_harness.TestCaseHarness) error {
 		"-lm", "-o", "inheritance", "inheritance.c")
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("inheritance.c does not compile: %s\n%s", err, string(out))
+		return fmt.Errorf("inheritance.c does not compile: %s\n%s", err, helpers.ExplainClangOutput(workDir, string(out)))
 	}
 	logger.Successf("inheritance.c compiles")
 
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/runner"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
@@ -39,7 +40,7 @@ func testPlurality(harness *test_case_harness.TestCaseHarness) error {
 	cmd := exec.Command("clang", "-o", "plurality", "plurality.c", "-I..", "-lm", "-Wall", "-Werror")
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("plurality.c does not compile: %s\n%s", err, string(out))
+		return fmt.Errorf("plurality.c does not compile: %s\n%s", err, helpers.ExplainClangOutput(workDir, string(out)))
 	}
 	logger.Successf("plurality compiles")
 
@@ -146,6 +147,13 @@ func testPlurality(harness *test_case_harness.TestCaseHarness) error {
 		logger.Successf("✓ %s", tc.name)
 	}
 
+	// 随机选举：运行完整的 ./plurality，与 Go 参考计票比对胜者
+	logger.Infof("Testing plurality on random elections...")
+	if err := checkRandomElections(workDir, pluralityElections); err != nil {
+		return err
+	}
+	logger.Successf("✓ plurality elects the correct winner in random elections")
+
 	// 清理测试文件
 	os.Remove(testFilePath)
 	os.Remove(filepath.Join(workDir, "plurality_test"))
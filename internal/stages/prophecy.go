@@ -0,0 +1,52 @@
+package stages
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bootllm/tester-utils/test_case_harness"
+	"github.com/bootllm/tester-utils/tester_definition"
+)
+
+// prophecyProblem: 把 students.csv 中的学生、学院与院长拆分成规范化的表
+var prophecyProblem = schemaProblem{
+	name:     "prophecy",
+	database: "roster.db",
+	schema:   "schema.sql",
+	script:   "prophecy.py",
+	csvFile:  "students.csv",
+	entities: []schemaEntity{
+		{name: "students", columns: []string{"student_name"}},
+		{name: "houses", columns: []string{"house", "head"}},
+	},
+}
+
+func prophecyTestCase() tester_definition.TestCase {
+	return tester_definition.TestCase{
+		Slug:     "prophecy",
+		Timeout:  60 * time.Second,
+		TestFunc: testProphecy,
+	}
+}
+
+func testProphecy(harness *test_case_harness.TestCaseHarness) error {
+	logger := harness.Logger
+	workDir := harness.SubmissionDir
+
+	// 1. 检查 schema.sql 和 prophecy.py 存在
+	logger.Infof("Checking schema.sql and prophecy.py exist...")
+	for _, file := range []string{prophecyProblem.schema, prophecyProblem.script} {
+		if !harness.FileExists(file) {
+			return fmt.Errorf("%s does not exist", file)
+		}
+	}
+	logger.Successf("schema.sql and prophecy.py exist")
+
+	// 2. 在全新的 roster.db 上建表、导入 staff 的 students.csv，并检查表结构与数据
+	if err := checkSchemaDesign(logger, workDir, prophecyProblem); err != nil {
+		return err
+	}
+
+	logger.Successf("All tests passed!")
+	return nil
+}
@@ -13,7 +13,7 @@ import (
 func readabilityTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "readability",
-		Timeout:  30 * time.Second,
+		Timeout:  helpers.FuzzTimeout(30 * time.Second),
 		TestFunc: testReadability,
 	}
 }
@@ -110,6 +110,13 @@ func testReadability(harness *test_case_harness.TestCaseHarness) error {
 		logger.Successf("✓ %s", tc.name)
 	}
 
+	// 4. 可选：模糊测试 (BOOTLLM_FUZZ=1，默认关闭)
+	if helpers.FuzzEnabled() {
+		if err := runFuzzPass(harness, "readability.c", textFuzzInputs()); err != nil {
+			return err
+		}
+	}
+
 	logger.Successf("All readability tests passed!")
 	return nil
 }
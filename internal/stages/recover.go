@@ -7,6 +7,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/random"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
 )
@@ -98,7 +100,7 @@ func testRecover(harness *test_case_harness.TestCaseHarness) error {
 		"-lm", "-o", "recover", "recover.c")
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("recover.c does not compile: %s\n%s", err, string(out))
+		return fmt.Errorf("recover.c does not compile: %s\n%s", err, helpers.ExplainClangOutput(workDir, string(out)))
 	}
 	logger.Successf("recover.c compiles")
 
@@ -155,7 +157,16 @@ func testRecover(harness *test_case_harness.TestCaseHarness) error {
 	}
 	logger.Successf("✓ recovers 049.jpg correctly")
 
-	// 9. 内存检查 (valgrind) - 清理后重新运行
+	// 9. 在每次随机生成的存储卡上运行，防止依赖固定的 card.raw
+	logger.Infof("Testing recovers JPEGs from generated forensic images...")
+	for i := 0; i < 3; i++ {
+		if err := checkGeneratedCard(workDir, random.RandomInt(1, 30)); err != nil {
+			return fmt.Errorf("generated card %d: %v", i+1, err)
+		}
+	}
+	logger.Successf("✓ recovers JPEGs from generated forensic images")
+
+	// 10. 内存检查 (valgrind) - 清理后重新运行
 	// 先清理之前生成的 JPEG 文件
 	for i := 0; i < 50; i++ {
 		os.Remove(filepath.Join(workDir, fmt.Sprintf("%03d.jpg", i)))
@@ -184,3 +195,24 @@ func testRecover(harness *test_case_harness.TestCaseHarness) error {
 	logger.Successf("All recover tests passed!")
 	return nil
 }
+
+// checkGeneratedCard 生成包含 count 张 JPEG 的存储卡，在临时目录中运行 recover 并逐个文件比对
+func checkGeneratedCard(workDir string, count int) error {
+	tmpDir, err := os.MkdirTemp("", "recover-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	card := helpers.GenerateForensicCard(count)
+	if err := os.WriteFile(filepath.Join(tmpDir, "card.raw"), card.Data, 0644); err != nil {
+		return err
+	}
+
+	cmd := exec.Command(filepath.Join(workDir, "recover"), "card.raw")
+	cmd.Dir = tmpDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("recover failed: %v\n%s", helpers.ExplainCmdCrash(cmd, err), string(out))
+	}
+	return card.CheckRecovered(tmpDir)
+}
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/runner"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
@@ -39,7 +40,7 @@ func testRunoff(harness *test_case_harness.TestCaseHarness) error {
 	cmd := exec.Command("clang", "-o", "runoff", "runoff.c", "-I..", "-lm", "-Wall", "-Werror")
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("runoff.c does not compile: %s\n%s", err, string(out))
+		return fmt.Errorf("runoff.c does not compile: %s\n%s", err, helpers.ExplainClangOutput(workDir, string(out)))
 	}
 	logger.Successf("runoff compiles")
 
@@ -261,6 +262,18 @@ func testRunoff(harness *test_case_harness.TestCaseHarness) error {
 		logger.Successf("✓ %s", tc.name)
 	}
 
+	// 端到端：以命令行参数和交互提示运行完整的 ./runoff
+	if err := checkElectionMain(logger, workDir, "runoff", runoffMainCases()); err != nil {
+		return err
+	}
+
+	// 随机选举：运行完整的 ./runoff，与 Go 参考计票比对胜者
+	logger.Infof("Testing runoff on random elections...")
+	if err := checkRandomElections(workDir, runoffElections); err != nil {
+		return err
+	}
+	logger.Successf("✓ runoff elects the correct winner in random elections")
+
 	// 清理测试文件
 	os.Remove(testFilePath)
 	os.Remove(filepath.Join(workDir, "runoff_test"))
@@ -0,0 +1,260 @@
+package stages
+
+import (
+	"database/sql"
+	"embed"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/logger"
+	"github.com/bootllm/tester-utils/runner"
+)
+
+// schemaDesignData 保存 schema 设计题目导入用的 staff 数据（schema_data/<problem>/），编译进二进制
+//
+//go:embed schema_data
+var schemaDesignData embed.FS
+
+// schemaEntity 是 CSV 中应单独成表的一组列，例如学院及其院长
+type schemaEntity struct {
+	name    string
+	columns []string
+}
+
+// schemaProblem 是一道 schema 设计题目：学生用 schema 建表，再用 script 把 csvFile 导入 database
+type schemaProblem struct {
+	name     string
+	database string
+	schema   string
+	script   string
+	csvFile  string
+	// entities 中每个实体的列必须放在同一张表中、每个不同的值只存一行，
+	// 且各实体的表之间通过外键相连
+	entities []schemaEntity
+	// allTablesNeedPrimaryKey 为 false 时只要求实体表有主键，
+	// 允许 assignments(student_id, house_id) 这类只含外键的关联表
+	allTablesNeedPrimaryKey bool
+}
+
+// loadSchemaCSV 读取题目的 staff CSV，返回表头和各行
+func loadSchemaCSV(problem schemaProblem) ([]byte, []string, [][]string, error) {
+	content, err := schemaDesignData.ReadFile(path.Join("schema_data", problem.name, problem.csvFile))
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("missing staff data %s: %v", problem.csvFile, err)
+	}
+	records, err := csv.NewReader(strings.NewReader(string(content))).ReadAll()
+	if err != nil || len(records) < 2 {
+		return nil, nil, nil, fmt.Errorf("invalid staff data %s: %v", problem.csvFile, err)
+	}
+	return content, records[0], records[1:], nil
+}
+
+// csvColumn 返回列名在表头中的位置
+func csvColumn(header []string, name string) int {
+	for i, h := range header {
+		if h == name {
+			return i
+		}
+	}
+	panic("unknown csv column " + name)
+}
+
+// checkSchemaDesign 在全新的数据库上依次执行学生的 schema 与导入脚本，
+// 然后检查表结构（主键、外键、实体拆分）并通过外键连接还原 CSV，确认没有数据丢失
+func checkSchemaDesign(logger *logger.Logger, workDir string, problem schemaProblem) error {
+	content, header, records, err := loadSchemaCSV(problem)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", problem.name+"-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	script, err := os.ReadFile(filepath.Join(workDir, problem.script))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", problem.script, err)
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, problem.script), script, 0644); err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, problem.csvFile), content, 0644); err != nil {
+		return err
+	}
+
+	// 1. 在空数据库上执行 schema
+	logger.Infof("Applying %s to an empty %s...", problem.schema, problem.database)
+	schemaSQL, err := os.ReadFile(filepath.Join(workDir, problem.schema))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", problem.schema, err)
+	}
+	dbPath := filepath.Join(tmpDir, problem.database)
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return err
+	}
+	err = helpers.ApplySQLScript(db, string(schemaSQL))
+	db.Close()
+	if err != nil {
+		return fmt.Errorf("%s failed at %v", problem.schema, err)
+	}
+
+	// 2. 运行导入脚本
+	logger.Infof("Running python3 %s to import %s...", problem.script, problem.csvFile)
+	r := runner.Run(tmpDir, "python3", problem.script).
+		WithTimeout(30 * time.Second).
+		Execute().
+		Exit(0)
+	if err := r.Error(); err != nil {
+		return fmt.Errorf("%s failed: %v", problem.script, helpers.ExplainPythonError(r, problem.script, err))
+	}
+
+	db, err = helpers.OpenReadOnlyDB(dbPath)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", problem.database, err)
+	}
+	defer db.Close()
+	schema, err := helpers.InspectSchema(db)
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s: %v", problem.database, err)
+	}
+	if len(schema.Tables) == 0 {
+		return fmt.Errorf("%s does not create any tables", problem.schema)
+	}
+	logger.Successf("✓ %s creates tables: %s", problem.schema, strings.Join(schema.TableNames(), ", "))
+
+	// 3. 每个实体单独成表，每个不同的值只存一次
+	var selected []helpers.ColumnRef
+	var expected [][]any
+	entityTables := map[string]string{}
+	for _, entity := range problem.entities {
+		logger.Infof("Checking %s are stored in their own table...", entity.name)
+		table, columns, err := findEntityTable(db, schema, header, records, entity)
+		if err != nil {
+			return err
+		}
+		if other, ok := entityTables[strings.ToLower(table)]; ok {
+			return fmt.Errorf("%s and %s are both stored in table %s; give each its own table and link them with a foreign key",
+				other, entity.name, table)
+		}
+		entityTables[strings.ToLower(table)] = entity.name
+		if len(schema.Table(table).PrimaryKey()) == 0 {
+			return fmt.Errorf("table %s has no PRIMARY KEY", table)
+		}
+
+		distinct := map[string]bool{}
+		for _, record := range records {
+			var key []string
+			for _, c := range entity.columns {
+				key = append(key, record[csvColumn(header, c)])
+			}
+			distinct[strings.Join(key, "\x00")] = true
+		}
+		if rows := schema.Table(table).Rows; rows != len(distinct) {
+			return fmt.Errorf("table %s has %d rows, but %s contains %d distinct %s; store each one exactly once",
+				table, rows, problem.csvFile, len(distinct), entity.name)
+		}
+		selected = append(selected, columns...)
+		logger.Successf("✓ %s are stored in %s (%d rows, primary key %s)",
+			entity.name, table, len(distinct), strings.Join(schema.Table(table).PrimaryKey(), ", "))
+	}
+	for _, record := range records {
+		var row []any
+		for _, entity := range problem.entities {
+			for _, c := range entity.columns {
+				row = append(row, record[csvColumn(header, c)])
+			}
+		}
+		expected = append(expected, row)
+	}
+
+	// 4. 关联表等其他表是否需要主键由题目决定
+	if problem.allTablesNeedPrimaryKey {
+		logger.Infof("Checking primary keys...")
+		for _, t := range schema.Tables {
+			if len(t.PrimaryKey()) == 0 {
+				return fmt.Errorf("table %s has no PRIMARY KEY", t.Name)
+			}
+		}
+		logger.Successf("✓ every table has a primary key")
+	}
+
+	// 5. 沿外键连接各实体的表，应还原出 CSV 中的每一行
+	logger.Infof("Checking foreign keys link the tables without losing data...")
+	query, err := schema.JoinQuery(selected)
+	if err != nil {
+		return fmt.Errorf("%v; declare FOREIGN KEY constraints in %s", err, problem.schema)
+	}
+	violations, err := helpers.ForeignKeyViolations(db)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("foreign keys point to missing rows:\n%s", strings.Join(violations, "\n"))
+	}
+	actual, err := helpers.QueryRows(db, query)
+	if err != nil {
+		return fmt.Errorf("failed to join tables: %v\n%s", err, query)
+	}
+	if diff := helpers.CompareResults(expected, actual, helpers.ResultSpec{}); diff != nil {
+		return fmt.Errorf("joining the tables along their foreign keys does not reproduce %s: %v\nquery: %s",
+			problem.csvFile, diff, query)
+	}
+	logger.Successf("✓ all %d rows of %s can be reconstructed through foreign keys", len(records), problem.csvFile)
+	return nil
+}
+
+// findEntityTable 找出存放实体各列的表：每列的不同值必须与 CSV 完全一致，且各列在同一张表中
+func findEntityTable(db *sql.DB, schema *helpers.Schema, header []string, records [][]string, entity schemaEntity) (string, []helpers.ColumnRef, error) {
+	// candidates[table] 是该表中与实体各列对应的列
+	candidates := map[string][]helpers.ColumnRef{}
+	var all []helpers.ColumnRef
+	for i, c := range entity.columns {
+		seen := map[string]bool{}
+		var values []string
+		for _, record := range records {
+			if v := record[csvColumn(header, c)]; !seen[v] {
+				seen[v] = true
+				values = append(values, v)
+			}
+		}
+		found, err := helpers.FindColumns(db, schema, values)
+		if err != nil {
+			return "", nil, err
+		}
+		if len(found) == 0 {
+			return "", nil, fmt.Errorf("no column contains exactly the %d distinct %s values from the CSV; some data was lost or changed on import",
+				len(values), c)
+		}
+		all = append(all, found...)
+		for _, f := range found {
+			if len(candidates[f.Table]) == i {
+				candidates[f.Table] = append(candidates[f.Table], f)
+			}
+		}
+	}
+	for _, t := range schema.TableNames() {
+		columns := candidates[t]
+		if len(columns) != len(entity.columns) {
+			continue
+		}
+		// 同样的值在其他表中再存一份就是冗余
+		for _, f := range all {
+			if f.Table != t {
+				return "", nil, fmt.Errorf("%s are stored twice, in %s and %s; keep them in one table and refer to them by id",
+					entity.name, t, f)
+			}
+		}
+		return t, columns, nil
+	}
+	return "", nil, fmt.Errorf("%s (%s) should be stored together in one table", entity.name, strings.Join(entity.columns, ", "))
+}
@@ -29,7 +29,7 @@ func getOnePointLetters() []string {
 func scrabbleTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "scrabble",
-		Timeout:  30 * time.Second,
+		Timeout:  helpers.FuzzTimeout(30 * time.Second),
 		TestFunc: testScrabble,
 	}
 }
@@ -223,6 +223,23 @@ func testScrabble(harness *test_case_harness.TestCaseHarness) error {
 	}
 	logger.Successf("✓ scoring accuracy test passed")
 
+	// 6. 可选：模糊测试 (BOOTLLM_FUZZ=1，默认关闭)
+	if helpers.FuzzEnabled() {
+		if err := runFuzzPass(harness, "scrabble.c", scrabbleFuzzInputs()); err != nil {
+			return err
+		}
+	}
+
 	logger.Successf("All scrabble tests passed!")
 	return nil
 }
+
+// scrabbleFuzzInputs 生成 scrabble 的模糊测试输入：两名玩家都输入不友好的单词
+func scrabbleFuzzInputs() []helpers.FuzzInput {
+	var inputs []helpers.FuzzInput
+	for _, text := range helpers.HostileTexts() {
+		inputs = append(inputs, helpers.FuzzInput{Stdin: text + "\n" + text + "\n"})
+	}
+	// 第一名玩家输入后直接 EOF
+	return append(inputs, helpers.FuzzInput{Stdin: "hello\n"}, helpers.FuzzInput{Stdin: ""})
+}
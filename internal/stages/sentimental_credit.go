@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/runner"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
@@ -63,7 +64,7 @@ func testSentimentalCredit(harness *test_case_harness.TestCaseHarness) error {
 			Exit(0)
 
 		if err := r.Error(); err != nil {
-			return fmt.Errorf("%s: %v", tc.name, err)
+			return fmt.Errorf("%s: %v", tc.name, helpers.ExplainPythonError(r, "credit.py", err))
 		}
 
 		logger.Successf("✓ %s", tc.name)
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/runner"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
@@ -48,7 +49,7 @@ func testSentimentalHello(harness *test_case_harness.TestCaseHarness) error {
 			Exit(0)
 
 		if err := r.Error(); err != nil {
-			return fmt.Errorf("test failed for input %q: %v", tc.name, err)
+			return fmt.Errorf("test failed for input %q: %v", tc.name, helpers.ExplainPythonError(r, "hello.py", err))
 		}
 
 		logger.Successf("✓ Output correct for input %q", tc.name)
@@ -7,6 +7,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/runner"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
@@ -89,7 +90,7 @@ func testSentimentalMarioMore(harness *test_case_harness.TestCaseHarness) error
 			Exit(0)
 
 		if err := r.Error(); err != nil {
-			return fmt.Errorf("%s: %v", tc.name, err)
+			return fmt.Errorf("%s: %v", tc.name, helpers.ExplainPythonError(r, "mario.py", err))
 		}
 
 		logger.Successf("✓ %s", tc.name)
@@ -115,7 +116,7 @@ func testSentimentalMarioMore(harness *test_case_harness.TestCaseHarness) error
 		Exit(0)
 
 	if err := r.Error(); err != nil {
-		return fmt.Errorf("rejects 9 and then accepts 2: %v", err)
+		return fmt.Errorf("rejects 9 and then accepts 2: %v", helpers.ExplainPythonError(r, "mario.py", err))
 	}
 
 	logger.Successf("✓ rejects 9 and then accepts 2")
@@ -3,13 +3,23 @@ package stages
 import (
 	"fmt"
 	"regexp"
-	"strings"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
 )
 
+// sortQuestions 是 sort/answers.txt 模板中的问题
+var sortQuestions = []helpers.AnswerQuestion{
+	{Label: "sort1 uses", MinWords: 1},
+	{Label: "How do you know?", Name: "sort1: How do you know?", MinWords: 1},
+	{Label: "sort2 uses", MinWords: 1},
+	{Label: "How do you know?", Name: "sort2: How do you know?", MinWords: 1},
+	{Label: "sort3 uses", MinWords: 1},
+	{Label: "How do you know?", Name: "sort3: How do you know?", MinWords: 1},
+}
+
 func sortTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "sort",
@@ -35,10 +45,10 @@ func testSort(harness *test_case_harness.TestCaseHarness) error {
 	}
 	answers := string(content)
 
-	// 3. 检查是否还有未回答的问题
+	// 3. 按模板逐题检查答案：每题都要作答，且给出各自的理由
 	logger.Infof("Checking all questions are answered...")
-	if strings.Contains(answers, "TODO") {
-		return fmt.Errorf("not all questions answered - still contains TODO")
+	if err := checkAnswersFile(answers, sortQuestions); err != nil {
+		return err
 	}
 	logger.Successf("all questions answered")
 
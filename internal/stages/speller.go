@@ -8,6 +8,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/random"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
 )
@@ -15,7 +17,7 @@ import (
 func spellerTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "speller",
-		Timeout:  60 * time.Second,
+		Timeout:  spellerTimeout(60 * time.Second),
 		TestFunc: testSpeller,
 	}
 }
@@ -36,7 +38,7 @@ func testSpeller(harness *test_case_harness.TestCaseHarness) error {
 	cmd := exec.Command("make", "speller")
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("speller does not compile: %s\n%s", err, string(out))
+		return fmt.Errorf("speller does not compile: %s\n%s", err, helpers.ExplainClangOutput(workDir, string(out)))
 	}
 	logger.Successf("speller compiles")
 
@@ -92,7 +94,7 @@ func testSpeller(harness *test_case_harness.TestCaseHarness) error {
 		cmd.Dir = workDir
 		out, err := cmd.CombinedOutput()
 		if err != nil {
-			return fmt.Errorf("speller failed on %s: %s\n%s", tc.dir, err, string(out))
+			return fmt.Errorf("speller failed on %s: %v\n%s", tc.dir, helpers.ExplainCmdCrash(cmd, err), string(out))
 		}
 
 		output := string(out)
@@ -119,6 +121,10 @@ func testSpeller(harness *test_case_harness.TestCaseHarness) error {
 			return fmt.Errorf("expected no misspelled words, but got: %v", misspelled)
 		}
 
+		if err := verifySpellerOutput(workDir, dictPath, textPath, output); err != nil {
+			return fmt.Errorf("%s: %v", tc.dir, err)
+		}
+
 		logger.Successf("✓ %s", tc.name)
 	}
 
@@ -130,12 +136,15 @@ func testSpeller(harness *test_case_harness.TestCaseHarness) error {
 	cmd.Dir = workDir
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return fmt.Errorf("speller failed on apostrophe/with: %s\n%s", err, string(out))
+		return fmt.Errorf("speller failed on apostrophe/with: %v\n%s", helpers.ExplainCmdCrash(cmd, err), string(out))
 	}
 	misspelled := extractMisspelledWords(string(out))
 	if len(misspelled) > 0 {
 		return fmt.Errorf("apostrophe test failed: expected no misspelled words, got: %v", misspelled)
 	}
+	if err := verifySpellerOutput(workDir, "apostrophe/with/dict", "apostrophe/with/text", string(out)); err != nil {
+		return fmt.Errorf("apostrophe/with: %v", err)
+	}
 	logger.Successf("✓ handles apostrophes properly")
 
 	// 测试大字典 (可选，验证性能)
@@ -145,10 +154,38 @@ func testSpeller(harness *test_case_harness.TestCaseHarness) error {
 		cmd.Dir = workDir
 		out, err := cmd.CombinedOutput()
 		if err != nil {
-			return fmt.Errorf("speller failed on large dictionary: %s\n%s", err, string(out))
+			return fmt.Errorf("speller failed on large dictionary: %v\n%s", helpers.ExplainCmdCrash(cmd, err), string(out))
+		}
+		if err := verifySpellerOutput(workDir, "large/dict", "large/text", string(out)); err != nil {
+			return fmt.Errorf("large: %v", err)
 		}
-		// 只检查程序能正常运行完成，不检查具体输出
 		logger.Successf("✓ handles large dictionary")
+
+		// 可选：堆内存分析 (BOOTLLM_MEMORY_PROFILE=1 或 BOOTLLM_BENCHMARK=1，默认关闭)
+		if spellerMemoryProfileEnabled() {
+			profileSpellerMemory(logger, workDir)
+		}
+	}
+
+	// 随机字典与文本，与 Go 参考实现逐词比对
+	logger.Infof("Testing random dictionaries and texts...")
+	if err := checkRandomSpellerPairs(workDir, 3); err != nil {
+		return err
+	}
+	logger.Successf("✓ matches reference output on random inputs")
+
+	// 可选：复杂度检查 (BOOTLLM_COMPLEXITY=1，默认关闭)
+	if helpers.ComplexityEnabled() {
+		if err := checkSpellerScaling(logger, workDir); err != nil {
+			return err
+		}
+	}
+
+	// 可选：性能基准与排行榜 (BOOTLLM_BENCHMARK=1，默认关闭)
+	if spellerBenchmarkEnabled() {
+		if err := benchmarkSpeller(logger, workDir); err != nil {
+			return err
+		}
 	}
 
 	// 内存检查 (valgrind) - 如果可用
@@ -180,6 +217,99 @@ func testSpeller(harness *test_case_harness.TestCaseHarness) error {
 	return nil
 }
 
+// verifySpellerOutput 用 Go 参考实现计算 dict/text 的期望结果，并与 speller 的完整输出比对
+// 相对路径相对于 workDir
+func verifySpellerOutput(workDir, dictPath, textPath, output string) error {
+	if !filepath.IsAbs(dictPath) {
+		dictPath = filepath.Join(workDir, dictPath)
+	}
+	if !filepath.IsAbs(textPath) {
+		textPath = filepath.Join(workDir, textPath)
+	}
+	dict, err := os.ReadFile(dictPath)
+	if err != nil {
+		return err
+	}
+	text, err := os.ReadFile(textPath)
+	if err != nil {
+		return err
+	}
+	actual, err := helpers.ParseSpellerOutput(output)
+	if err != nil {
+		return err
+	}
+	return helpers.CompareSpellerResults(helpers.ReferenceSpeller(string(dict), string(text)), actual)
+}
+
+// checkRandomSpellerPairs 生成 count 组随机字典与文本运行 speller
+func checkRandomSpellerPairs(workDir string, count int) error {
+	tmpDir, err := os.MkdirTemp("", "speller-random-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	for i := 0; i < count; i++ {
+		dict := randomDictionary(random.RandomInt(500, 3000))
+		text := randomSpellerText(dict, random.RandomInt(1000, 5000))
+		dictPath := filepath.Join(tmpDir, fmt.Sprintf("dict-%d", i))
+		textPath := filepath.Join(tmpDir, fmt.Sprintf("text-%d", i))
+		if err := os.WriteFile(dictPath, []byte(strings.Join(dict, "\n")+"\n"), 0644); err != nil {
+			return err
+		}
+		if err := os.WriteFile(textPath, []byte(text), 0644); err != nil {
+			return err
+		}
+
+		cmd := exec.Command("./speller", dictPath, textPath)
+		cmd.Dir = workDir
+		out, err := cmd.Output()
+		if err != nil {
+			return fmt.Errorf("speller failed on random input %d: %v", i+1, helpers.ExplainCmdCrash(cmd, err))
+		}
+		if err := verifySpellerOutput(workDir, dictPath, textPath, string(out)); err != nil {
+			// 保留失败的输入供学生复现
+			kept, keepErr := os.MkdirTemp("", "speller-failing-")
+			if keepErr == nil {
+				os.Rename(dictPath, filepath.Join(kept, "dict"))
+				os.Rename(textPath, filepath.Join(kept, "text"))
+				return fmt.Errorf("random input %d (saved to %s): %v", i+1, kept, err)
+			}
+			return fmt.Errorf("random input %d: %v", i+1, err)
+		}
+	}
+	return nil
+}
+
+// randomSpellerText 在 randomText 的基础上混入各种分词边界情况：
+// 大写、所有格、开头的撇号、含数字的串、超长单词和标点
+func randomSpellerText(dict []string, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		w := dict[random.RandomInt(0, len(dict))]
+		switch random.RandomInt(0, 12) {
+		case 0:
+			w = strings.ToUpper(w)
+		case 1:
+			w = strings.ToUpper(w[:1]) + w[1:]
+		case 2:
+			w = randomWord(1, 14)
+		case 3:
+			w = "'" + w
+		case 4:
+			w = w + fmt.Sprint(random.RandomInt(0, 100))
+		case 5:
+			w = randomWord(helpers.SpellerMaxLength-1, helpers.SpellerMaxLength+3)
+		case 6:
+			w = w + "'s"
+		}
+		b.WriteString(w)
+		b.WriteString([]string{" ", " ", " ", ", ", ". ", "\n", "-", "; ", "\"", "é"}[random.RandomInt(0, 10)])
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
 // extractMisspelledWords 从 speller 输出中提取拼错的单词
 func extractMisspelledWords(output string) []string {
 	lines := strings.Split(output, "\n")
@@ -0,0 +1,456 @@
+package stages
+
+import (
+	"database/sql"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/logger"
+)
+
+const (
+	// SpellerBenchmarkRuns 是每段文本的运行次数，取各阶段耗时的中位数
+	SpellerBenchmarkRuns = 5
+
+	// SpellerLeaderboardSize 是打印排行榜时显示的人数
+	SpellerLeaderboardSize = 10
+
+	// SpellerMemoryWarningRatio 堆内存峰值或分配总量超过 staff 基线的这个倍数时给出警告
+	SpellerMemoryWarningRatio = 4
+
+	// defaultBenchmarkDB 是未设置 BOOTLLM_BENCHMARK_DB 时使用的数据库文件名，
+	// 位于用户缓存目录下，不写入提交目录
+	defaultBenchmarkDB = "speller_benchmark.db"
+)
+
+// spellerBenchmarkEnabled 判断是否开启 speller 基准测试（BOOTLLM_BENCHMARK=1）
+func spellerBenchmarkEnabled() bool {
+	return os.Getenv("BOOTLLM_BENCHMARK") == "1"
+}
+
+// spellerMemoryProfileEnabled 判断是否统计 speller 的堆内存：
+// BOOTLLM_MEMORY_PROFILE=1 单独开启，或随 BOOTLLM_BENCHMARK=1 一起报告
+func spellerMemoryProfileEnabled() bool {
+	return helpers.MemoryProfileEnabled() || spellerBenchmarkEnabled()
+}
+
+// spellerTimeout 按开启的可选检查追加 stage 超时：
+// 内存分析对学生与 staff 各运行一次 massif 和 malloc shim，共四次 MemoryProfileTimeout；
+// 基准测试追加 60 秒（学生与 staff 各运行 SpellerBenchmarkRuns 次）
+func spellerTimeout(base time.Duration) time.Duration {
+	timeout := helpers.ComplexityTimeout(base)
+	if spellerMemoryProfileEnabled() {
+		timeout += 4 * helpers.MemoryProfileTimeout
+	}
+	if spellerBenchmarkEnabled() {
+		timeout += 60 * time.Second
+	}
+	return timeout
+}
+
+// staffDictionary 是 staff 解答的 dictionary.c，编译进二进制
+// 基准测试与内存分析时把它与学生的 speller.c、dictionary.h、Makefile 一起编译，
+// 在同一台机器、同一份输入上测出 staff 基线，比例因此不受机器快慢影响
+//
+//go:embed speller_staff/dictionary.c
+var staffDictionary []byte
+
+// spellerTimes 表示 speller 输出的各阶段耗时（秒）
+type spellerTimes struct {
+	Load   float64
+	Check  float64
+	Size   float64
+	Unload float64
+	Total  float64
+}
+
+// buildStaffSpeller 在临时目录中用 staff 的 dictionary.c 编译 speller，返回可执行文件的绝对路径
+func buildStaffSpeller(workDir string) (string, func(), error) {
+	tmpDir, err := os.MkdirTemp("", "speller-staff-")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup := func() { os.RemoveAll(tmpDir) }
+
+	for _, file := range []string{"speller.c", "dictionary.h", "Makefile"} {
+		content, err := os.ReadFile(filepath.Join(workDir, file))
+		if err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("failed to read %s: %v", file, err)
+		}
+		if err := os.WriteFile(filepath.Join(tmpDir, file), content, 0644); err != nil {
+			cleanup()
+			return "", nil, err
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "dictionary.c"), staffDictionary, 0644); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	cmd := exec.Command("make", "speller")
+	cmd.Dir = tmpDir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("staff speller does not compile: %v\n%s", err, string(out))
+	}
+	return filepath.Join(tmpDir, "speller"), cleanup, nil
+}
+
+// measureSpellerTimes 在 workDir 中运行 program SpellerBenchmarkRuns 次，返回各阶段耗时的中位数
+func measureSpellerTimes(workDir, program, dict, text string) (spellerTimes, error) {
+	var runs []spellerTimes
+	for i := 0; i < SpellerBenchmarkRuns; i++ {
+		cmd := exec.Command(program, dict, text)
+		cmd.Dir = workDir
+		out, err := cmd.Output()
+		if err != nil {
+			return spellerTimes{}, fmt.Errorf("speller failed during benchmark: %v", err)
+		}
+		t, err := parseSpellerTimes(string(out))
+		if err != nil {
+			return spellerTimes{}, err
+		}
+		runs = append(runs, t)
+	}
+	return medianSpellerTimes(runs), nil
+}
+
+// spellerTimeRegex 匹配 "TIME IN load:         0.02"
+var spellerTimeRegex = regexp.MustCompile(`(?m)^TIME IN (load|check|size|unload|TOTAL):\s*([0-9.]+)`)
+
+// parseSpellerTimes 从 speller 输出中解析 TIME IN 各行
+func parseSpellerTimes(output string) (spellerTimes, error) {
+	var t spellerTimes
+	found := map[string]bool{}
+	for _, m := range spellerTimeRegex.FindAllStringSubmatch(output, -1) {
+		v, err := strconv.ParseFloat(m[2], 64)
+		if err != nil {
+			return t, fmt.Errorf("invalid time %q for %s", m[2], m[1])
+		}
+		found[m[1]] = true
+		switch m[1] {
+		case "load":
+			t.Load = v
+		case "check":
+			t.Check = v
+		case "size":
+			t.Size = v
+		case "unload":
+			t.Unload = v
+		case "TOTAL":
+			t.Total = v
+		}
+	}
+	for _, phase := range []string{"load", "check", "size", "unload", "TOTAL"} {
+		if !found[phase] {
+			return t, fmt.Errorf("speller output is missing \"TIME IN %s\"", phase)
+		}
+	}
+	return t, nil
+}
+
+// medianSpellerTimes 对每个阶段分别取中位数
+func medianSpellerTimes(runs []spellerTimes) spellerTimes {
+	pick := func(get func(spellerTimes) float64) float64 {
+		vs := make([]float64, len(runs))
+		for i, r := range runs {
+			vs[i] = get(r)
+		}
+		return median(vs)
+	}
+	return spellerTimes{
+		Load:   pick(func(t spellerTimes) float64 { return t.Load }),
+		Check:  pick(func(t spellerTimes) float64 { return t.Check }),
+		Size:   pick(func(t spellerTimes) float64 { return t.Size }),
+		Unload: pick(func(t spellerTimes) float64 { return t.Unload }),
+		Total:  pick(func(t spellerTimes) float64 { return t.Total }),
+	}
+}
+
+// median 返回中位数（偶数个时取中间两个的平均值）
+func median(vs []float64) float64 {
+	if len(vs) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), vs...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// ratio 返回 student / staff，staff 为 0 时返回 0（表示无法比较）
+func ratio(student, staff float64) float64 {
+	if staff == 0 {
+		return 0
+	}
+	return student / staff
+}
+
+// benchmarkDBPath 返回排行榜数据库路径：BOOTLLM_BENCHMARK_DB，
+// 否则为用户缓存目录（不可用时为临时目录）下的 llm100x-tester/speller_benchmark.db
+func benchmarkDBPath() (string, error) {
+	if path := os.Getenv("BOOTLLM_BENCHMARK_DB"); path != "" {
+		return path, nil
+	}
+	dir, err := testerCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, defaultBenchmarkDB), nil
+}
+
+// testerCacheDir 返回 tester 在提交目录之外保存文件的目录：
+// 用户缓存目录（不可用时为临时目录）下的 llm100x-tester，不存在时创建
+func testerCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	dir = filepath.Join(dir, "llm100x-tester")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %v", dir, err)
+	}
+	return dir, nil
+}
+
+// benchmarkStudent 返回写入排行榜的名字：BOOTLLM_STUDENT，否则为当前用户名
+func benchmarkStudent() string {
+	if name := os.Getenv("BOOTLLM_STUDENT"); name != "" {
+		return name
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// benchmarkSpeller 对 large/text 多次运行学生与 staff 的 speller，对比后写入排行榜
+func benchmarkSpeller(logger *logger.Logger, workDir string) error {
+	dict, text := "large/dict", "large/text"
+	logger.Infof("Benchmarking speller on %s (%d runs)...", text, SpellerBenchmarkRuns)
+
+	result, err := measureSpellerTimes(workDir, "./speller", dict, text)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Median times: load %.2fs, check %.2fs, size %.2fs, unload %.2fs, total %.2fs",
+		result.Load, result.Check, result.Size, result.Unload, result.Total)
+
+	// staff 基线在同一台机器上现场测量，编译或运行失败时只跳过对比
+	staffRatio := 0.0
+	staffSpeller, cleanup, err := buildStaffSpeller(workDir)
+	if err == nil {
+		defer cleanup()
+		var staff spellerTimes
+		if staff, err = measureSpellerTimes(workDir, staffSpeller, dict, text); err == nil {
+			staffRatio = ratio(result.Total, staff.Total)
+			logger.Infof("Staff solution: load %.2fs, check %.2fs, size %.2fs, unload %.2fs, total %.2fs",
+				staff.Load, staff.Check, staff.Size, staff.Unload, staff.Total)
+			logger.Infof("Compared with staff: load %.1fx, check %.1fx, unload %.1fx, total %.1fx",
+				ratio(result.Load, staff.Load), ratio(result.Check, staff.Check),
+				ratio(result.Unload, staff.Unload), staffRatio)
+		}
+	}
+	if err != nil {
+		logger.Infof("Staff baseline unavailable, skipping comparison: %v", err)
+	}
+
+	dbPath, err := benchmarkDBPath()
+	if err != nil {
+		return err
+	}
+	board, err := openSpellerLeaderboard(dbPath)
+	if err != nil {
+		return err
+	}
+	defer board.Close()
+
+	if err := board.record(benchmarkStudent(), text, result, staffRatio); err != nil {
+		return err
+	}
+
+	entries, err := board.top(text, SpellerLeaderboardSize)
+	if err != nil {
+		return err
+	}
+	logger.Infof("Leaderboard for %s (%s):", text, dbPath)
+	for i, e := range entries {
+		logger.Infof("  %2d. %-20s total %.2fs (load %.2f, check %.2f, size %.2f, unload %.2f)",
+			i+1, e.Student, e.Times.Total, e.Times.Load, e.Times.Check, e.Times.Size, e.Times.Unload)
+	}
+
+	if exportPath := os.Getenv("BOOTLLM_BENCHMARK_EXPORT"); exportPath != "" {
+		if err := board.exportCSV(exportPath); err != nil {
+			return err
+		}
+		logger.Infof("Leaderboard exported to %s", exportPath)
+	}
+
+	logger.Successf("✓ speller benchmark recorded")
+	return nil
+}
+
+// profileSpellerMemory 统计 speller 在大字典上的堆内存，与 staff 解答相差悬殊时给出警告
+// 只报告不判错：内存用量取决于数据结构的设计取舍
+func profileSpellerMemory(logger *logger.Logger, workDir string) {
+	dict, text := "large/dict", "large/text"
+	logger.Infof("Profiling memory usage on %s...", text)
+
+	profile, err := helpers.ProfileMemory(workDir, "./speller", dict, text)
+	if err != nil {
+		logger.Infof("Memory profiling unavailable, skipping: %v", err)
+		return
+	}
+	logger.Infof("Memory usage: %s", profile)
+
+	staffSpeller, cleanup, err := buildStaffSpeller(workDir)
+	if err != nil {
+		logger.Infof("Staff baseline unavailable, skipping comparison: %v", err)
+		return
+	}
+	defer cleanup()
+	staff, err := helpers.ProfileMemory(workDir, staffSpeller, dict, text)
+	if err != nil {
+		logger.Infof("Staff baseline unavailable, skipping comparison: %v", err)
+		return
+	}
+	logger.Infof("Staff solution: %s", staff)
+
+	if r := ratio(float64(profile.PeakHeap), float64(staff.PeakHeap)); r > SpellerMemoryWarningRatio {
+		logger.Errorf("Warning: peak heap is %.1fx the staff solution's; check for oversized nodes or an enormous table", r)
+	}
+	if profile.TotalAllocated >= 0 && staff.TotalAllocated > 0 {
+		if r := ratio(float64(profile.TotalAllocated), float64(staff.TotalAllocated)); r > SpellerMemoryWarningRatio {
+			logger.Errorf("Warning: total allocations are %.1fx the staff solution's; check for allocations inside check()", r)
+		}
+	}
+}
+
+// spellerLeaderboard 是保存 speller 成绩的本地 SQLite 数据库
+type spellerLeaderboard struct {
+	db *sql.DB
+}
+
+// leaderboardEntry 是某个学生在某段文本上的最好成绩
+type leaderboardEntry struct {
+	Student string
+	Times   spellerTimes
+}
+
+// openSpellerLeaderboard 打开（必要时创建）排行榜数据库
+func openSpellerLeaderboard(path string) (*spellerLeaderboard, error) {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	_, err = db.Exec(`CREATE TABLE IF NOT EXISTS speller_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		student TEXT NOT NULL,
+		text TEXT NOT NULL,
+		load REAL NOT NULL,
+		"check" REAL NOT NULL,
+		size REAL NOT NULL,
+		unload REAL NOT NULL,
+		total REAL NOT NULL,
+		staff_ratio REAL NOT NULL,
+		recorded_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize %s: %v", path, err)
+	}
+	return &spellerLeaderboard{db: db}, nil
+}
+
+func (b *spellerLeaderboard) Close() error {
+	return b.db.Close()
+}
+
+// record 追加一次成绩
+func (b *spellerLeaderboard) record(student, text string, t spellerTimes, staffRatio float64) error {
+	_, err := b.db.Exec(`INSERT INTO speller_runs
+		(student, text, load, "check", size, unload, total, staff_ratio, recorded_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		student, text, t.Load, t.Check, t.Size, t.Unload, t.Total, staffRatio,
+		time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return fmt.Errorf("failed to record benchmark: %v", err)
+	}
+	return nil
+}
+
+// top 返回每个学生在 text 上的最好成绩，按总耗时升序
+func (b *spellerLeaderboard) top(text string, limit int) ([]leaderboardEntry, error) {
+	rows, err := b.db.Query(`SELECT student, load, "check", size, unload, total FROM speller_runs r
+		WHERE text = ? AND id = (
+			SELECT id FROM speller_runs WHERE text = r.text AND student = r.student
+			ORDER BY total, id LIMIT 1
+		)
+		ORDER BY total, student LIMIT ?`, text, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query leaderboard: %v", err)
+	}
+	defer rows.Close()
+
+	var entries []leaderboardEntry
+	for rows.Next() {
+		var e leaderboardEntry
+		if err := rows.Scan(&e.Student, &e.Times.Load, &e.Times.Check, &e.Times.Size, &e.Times.Unload, &e.Times.Total); err != nil {
+			return nil, fmt.Errorf("failed to read leaderboard: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// exportCSV 导出所有成绩
+func (b *spellerLeaderboard) exportCSV(path string) error {
+	rows, err := b.db.Query(`SELECT student, text, load, "check", size, unload, total, staff_ratio, recorded_at
+		FROM speller_runs ORDER BY text, total`)
+	if err != nil {
+		return fmt.Errorf("failed to query leaderboard: %v", err)
+	}
+	defer rows.Close()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	w.Write([]string{"student", "text", "load", "check", "size", "unload", "total", "staff_ratio", "recorded_at"})
+	for rows.Next() {
+		var student, text, recordedAt string
+		var load, check, size, unload, total, staffRatio float64
+		if err := rows.Scan(&student, &text, &load, &check, &size, &unload, &total, &staffRatio, &recordedAt); err != nil {
+			return fmt.Errorf("failed to read leaderboard: %v", err)
+		}
+		w.Write([]string{student, text,
+			strconv.FormatFloat(load, 'f', 2, 64), strconv.FormatFloat(check, 'f', 2, 64),
+			strconv.FormatFloat(size, 'f', 2, 64), strconv.FormatFloat(unload, 'f', 2, 64),
+			strconv.FormatFloat(total, 'f', 2, 64), strconv.FormatFloat(staffRatio, 'f', 2, 64),
+			recordedAt})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return rows.Err()
+}
@@ -0,0 +1,286 @@
+package stages
+
+import (
+	"encoding/csv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bootllm/tester-utils/random"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSpellerTimes(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    spellerTimes
+		wantErr string
+	}{
+		{
+			name: "all phases",
+			output: "WORDS MISSPELLED:     955\nWORDS IN DICTIONARY:  143091\nWORDS IN TEXT:        17756\n" +
+				"TIME IN load:         0.02\nTIME IN check:        0.01\nTIME IN size:         0.00\n" +
+				"TIME IN unload:       0.01\nTIME IN TOTAL:        0.04\n",
+			want: spellerTimes{Load: 0.02, Check: 0.01, Size: 0, Unload: 0.01, Total: 0.04},
+		},
+		{
+			name:    "missing unload",
+			output:  "TIME IN load: 1.5\nTIME IN check: 2\nTIME IN size: 0\nTIME IN TOTAL: 3.5\n",
+			wantErr: `speller output is missing "TIME IN unload"`,
+		},
+		{
+			name:    "no times",
+			output:  "MISSPELLED WORDS\n\n",
+			wantErr: `speller output is missing "TIME IN load"`,
+		},
+		{
+			name:    "invalid number",
+			output:  "TIME IN load: 1.2.3\n",
+			wantErr: `invalid time "1.2.3" for load`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSpellerTimes(tt.output)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestMedian(t *testing.T) {
+	tests := []struct {
+		name string
+		vs   []float64
+		want float64
+	}{
+		{"empty", nil, 0},
+		{"single", []float64{3}, 3},
+		{"odd unsorted", []float64{5, 1, 3}, 3},
+		{"even averages middle pair", []float64{4, 1, 3, 2}, 2.5},
+		{"outlier ignored", []float64{0.1, 0.1, 9, 0.2, 0.1}, 0.1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, median(tt.vs), 1e-9)
+		})
+	}
+
+	// median 不能改变调用者的切片顺序
+	vs := []float64{3, 1, 2}
+	median(vs)
+	assert.Equal(t, []float64{3, 1, 2}, vs)
+}
+
+func TestMedianSpellerTimes(t *testing.T) {
+	runs := []spellerTimes{
+		{Load: 0.3, Check: 0.1, Size: 0, Unload: 0.2, Total: 0.6},
+		{Load: 0.1, Check: 0.5, Size: 0, Unload: 0.1, Total: 0.7},
+		{Load: 0.2, Check: 0.2, Size: 0.1, Unload: 0.3, Total: 0.8},
+	}
+	assert.Equal(t, spellerTimes{Load: 0.2, Check: 0.2, Size: 0, Unload: 0.2, Total: 0.7}, medianSpellerTimes(runs))
+}
+
+func TestSpellerLeaderboard(t *testing.T) {
+	dir := t.TempDir()
+	board, err := openSpellerLeaderboard(filepath.Join(dir, "board.db"))
+	require.NoError(t, err)
+	defer board.Close()
+
+	runs := []struct {
+		student string
+		text    string
+		total   float64
+	}{
+		{"alice", "large/text", 0.9},
+		{"bob", "large/text", 0.5},
+		{"alice", "large/text", 0.4},
+		{"carol", "large/text", 0.7},
+		{"dave", "other/text", 0.1},
+	}
+	for _, r := range runs {
+		require.NoError(t, board.record(r.student, r.text, spellerTimes{Load: r.total / 2, Total: r.total}, r.total/0.2))
+	}
+
+	// 每个学生只保留最好的一次，按总耗时升序，且只包含同一段文本
+	entries, err := board.top("large/text", 10)
+	require.NoError(t, err)
+	var students []string
+	for _, e := range entries {
+		students = append(students, e.Student)
+	}
+	assert.Equal(t, []string{"alice", "bob", "carol"}, students)
+	assert.Equal(t, spellerTimes{Load: 0.2, Total: 0.4}, entries[0].Times)
+
+	entries, err = board.top("large/text", 2)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	exportPath := filepath.Join(dir, "board.csv")
+	require.NoError(t, board.exportCSV(exportPath))
+	f, err := os.Open(exportPath)
+	require.NoError(t, err)
+	defer f.Close()
+	records, err := csv.NewReader(f).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, len(runs)+1)
+	assert.Equal(t, []string{"student", "text", "load", "check", "size", "unload", "total", "staff_ratio", "recorded_at"}, records[0])
+	assert.Equal(t, []string{"alice", "large/text", "0.20", "0.00", "0.00", "0.00", "0.40", "2.00"}, records[1][:8])
+	assert.Equal(t, "dave", records[len(records)-1][0])
+}
+
+func TestBenchmarkDBPathOutsideSubmission(t *testing.T) {
+	t.Setenv("BOOTLLM_BENCHMARK_DB", "")
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	path, err := benchmarkDBPath()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(os.Getenv("XDG_CACHE_HOME"), "llm100x-tester", defaultBenchmarkDB), path)
+
+	t.Setenv("BOOTLLM_BENCHMARK_DB", "/tmp/custom.db")
+	path, err = benchmarkDBPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/tmp/custom.db", path)
+}
+
+// spellerSkeleton 是按 CS50 speller.c 的分词与计时逻辑精简的驱动程序
+const spellerSkeleton = `#include <ctype.h>
+#include <stdio.h>
+#include <sys/resource.h>
+#include "dictionary.h"
+
+static double seconds(struct rusage *b, struct rusage *a)
+{
+    return ((a->ru_utime.tv_sec * 1000000 + a->ru_utime.tv_usec) - (b->ru_utime.tv_sec * 1000000 + b->ru_utime.tv_usec)) / 1000000.0;
+}
+
+int main(int argc, char *argv[])
+{
+    struct rusage before, after;
+    double t_load, t_check = 0, t_size, t_unload;
+    getrusage(RUSAGE_SELF, &before);
+    bool loaded = load(argv[1]);
+    getrusage(RUSAGE_SELF, &after);
+    if (!loaded) return 1;
+    t_load = seconds(&before, &after);
+    FILE *file = fopen(argv[2], "r");
+    if (file == NULL) return 1;
+    printf("\nMISSPELLED WORDS\n\n");
+    int index = 0, misspellings = 0, words = 0;
+    char word[LENGTH + 1];
+    char c;
+    while (fread(&c, sizeof(char), 1, file))
+    {
+        if (isalpha(c) || (c == '\'' && index > 0))
+        {
+            word[index++] = c;
+            if (index > LENGTH)
+            {
+                while (fread(&c, sizeof(char), 1, file) && isalpha(c));
+                index = 0;
+            }
+        }
+        else if (isdigit(c))
+        {
+            while (fread(&c, sizeof(char), 1, file) && isalnum(c));
+            index = 0;
+        }
+        else if (index > 0)
+        {
+            word[index] = '\0';
+            words++;
+            getrusage(RUSAGE_SELF, &before);
+            bool misspelled = !check(word);
+            getrusage(RUSAGE_SELF, &after);
+            t_check += seconds(&before, &after);
+            if (misspelled)
+            {
+                printf("%s\n", word);
+                misspellings++;
+            }
+            index = 0;
+        }
+    }
+    fclose(file);
+    getrusage(RUSAGE_SELF, &before);
+    unsigned int n = size();
+    getrusage(RUSAGE_SELF, &after);
+    t_size = seconds(&before, &after);
+    getrusage(RUSAGE_SELF, &before);
+    bool unloaded = unload();
+    getrusage(RUSAGE_SELF, &after);
+    if (!unloaded) return 1;
+    t_unload = seconds(&before, &after);
+    printf("\nWORDS MISSPELLED:     %d\n", misspellings);
+    printf("WORDS IN DICTIONARY:  %d\n", n);
+    printf("WORDS IN TEXT:        %d\n", words);
+    printf("TIME IN load:         %.2f\n", t_load);
+    printf("TIME IN check:        %.2f\n", t_check);
+    printf("TIME IN size:         %.2f\n", t_size);
+    printf("TIME IN unload:       %.2f\n", t_unload);
+    printf("TIME IN TOTAL:        %.2f\n\n", t_load + t_check + t_size + t_unload);
+    return 0;
+}
+`
+
+const dictionaryHeader = `#ifndef DICTIONARY_H
+#define DICTIONARY_H
+#include <stdbool.h>
+#define LENGTH 45
+bool check(const char *word);
+unsigned int hash(const char *word);
+bool load(const char *dictionary);
+unsigned int size(void);
+bool unload(void);
+#endif
+`
+
+const spellerMakefile = "speller:\n\tcc -O0 -std=c11 -Wall -Werror -o speller speller.c dictionary.c\n"
+
+func TestStaffSpeller(t *testing.T) {
+	if _, err := exec.LookPath("cc"); err != nil {
+		t.Skip("no C compiler")
+	}
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make not available")
+	}
+	random.Init()
+
+	workDir := t.TempDir()
+	for name, content := range map[string]string{
+		"speller.c": spellerSkeleton, "dictionary.h": dictionaryHeader, "Makefile": spellerMakefile,
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(workDir, name), []byte(content), 0644))
+	}
+	dict := randomDictionary(2000)
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "dict"), []byte(strings.Join(dict, "\n")+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "text"), []byte(randomSpellerText(dict, 3000)), 0644))
+
+	staffSpeller, cleanup, err := buildStaffSpeller(workDir)
+	require.NoError(t, err)
+	defer cleanup()
+	assert.True(t, filepath.IsAbs(staffSpeller))
+
+	cmd := exec.Command(staffSpeller, "dict", "text")
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	require.NoError(t, err)
+	assert.NoError(t, verifySpellerOutput(workDir, "dict", "text", string(out)))
+
+	_, err = measureSpellerTimes(workDir, staffSpeller, "dict", "text")
+	assert.NoError(t, err)
+
+	// 缺少骨架文件时返回错误，由调用方跳过对比
+	require.NoError(t, os.Remove(filepath.Join(workDir, "Makefile")))
+	_, _, err = buildStaffSpeller(workDir)
+	assert.ErrorContains(t, err, "failed to read Makefile")
+}
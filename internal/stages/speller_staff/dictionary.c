@@ -0,0 +1,94 @@
+// staff 解答：链地址哈希表，基准测试与内存分析用它作为对比基线
+
+#include <ctype.h>
+#include <stdbool.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <strings.h>
+
+#include "dictionary.h"
+
+// 哈希表中的一个节点
+typedef struct node
+{
+    char word[LENGTH + 1];
+    struct node *next;
+} node;
+
+// 桶的数量
+#define BUCKETS 65536
+
+static node *table[BUCKETS];
+static unsigned int words = 0;
+
+// 不区分大小写的 djb2 哈希
+unsigned int hash(const char *word)
+{
+    unsigned int h = 5381;
+    for (const char *p = word; *p != '\0'; p++)
+    {
+        h = ((h << 5) + h) + tolower((unsigned char) *p);
+    }
+    return h % BUCKETS;
+}
+
+bool check(const char *word)
+{
+    for (node *n = table[hash(word)]; n != NULL; n = n->next)
+    {
+        if (strcasecmp(n->word, word) == 0)
+        {
+            return true;
+        }
+    }
+    return false;
+}
+
+bool load(const char *dictionary)
+{
+    FILE *file = fopen(dictionary, "r");
+    if (file == NULL)
+    {
+        return false;
+    }
+
+    char word[LENGTH + 1];
+    while (fscanf(file, "%45s", word) == 1)
+    {
+        node *n = malloc(sizeof(node));
+        if (n == NULL)
+        {
+            fclose(file);
+            return false;
+        }
+        strcpy(n->word, word);
+        unsigned int h = hash(word);
+        n->next = table[h];
+        table[h] = n;
+        words++;
+    }
+    fclose(file);
+    return true;
+}
+
+unsigned int size(void)
+{
+    return words;
+}
+
+bool unload(void)
+{
+    for (int i = 0; i < BUCKETS; i++)
+    {
+        node *n = table[i];
+        while (n != NULL)
+        {
+            node *next = n->next;
+            free(n);
+            n = next;
+        }
+        table[i] = NULL;
+    }
+    return true;
+}
@@ -0,0 +1,78 @@
+package stages
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/random"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSpellerEnv 让测试二进制扮演 speller：用参考实现检查 argv 中的字典与文本
+const fakeSpellerEnv = "LLM100X_FAKE_SPELLER"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(fakeSpellerEnv); mode != "" {
+		os.Exit(runFakeSpeller(mode, os.Args[len(os.Args)-2], os.Args[len(os.Args)-1]))
+	}
+	os.Exit(m.Run())
+}
+
+func runFakeSpeller(mode, dictPath, textPath string) int {
+	dict, err := os.ReadFile(dictPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	text, err := os.ReadFile(textPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	result := helpers.ReferenceSpeller(string(dict), string(text))
+	if mode == "wrong" {
+		result.Misspelled = append(result.Misspelled, "bogus")
+	}
+	fmt.Printf("\nMISSPELLED WORDS\n\n%s\n\n", strings.Join(result.Misspelled, "\n"))
+	fmt.Printf("WORDS MISSPELLED:     %d\n", len(result.Misspelled))
+	fmt.Printf("WORDS IN DICTIONARY:  %d\n", result.Dictionary)
+	fmt.Printf("WORDS IN TEXT:        %d\n", result.Text)
+	return 0
+}
+
+// installFakeSpeller 在 workDir 下放置一个转发到测试二进制的 ./speller
+func installFakeSpeller(t *testing.T, mode string) string {
+	workDir := t.TempDir()
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	script := fmt.Sprintf("#!/bin/sh\n%s=%s exec %q \"$@\"\n", fakeSpellerEnv, mode, exe)
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "speller"), []byte(script), 0755))
+	return workDir
+}
+
+func TestCheckRandomSpellerPairs(t *testing.T) {
+	random.Init()
+	workDir := installFakeSpeller(t, "reference")
+	assert.NoError(t, checkRandomSpellerPairs(workDir, 2))
+
+	workDir = installFakeSpeller(t, "wrong")
+	err := checkRandomSpellerPairs(workDir, 1)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "random input 1")
+	assert.Contains(t, err.Error(), `"bogus"`)
+}
+
+func TestVerifySpellerOutputRelativePaths(t *testing.T) {
+	workDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(workDir, "basic"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "basic", "dict"), []byte("cat\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(workDir, "basic", "text"), []byte("cat dog\n"), 0644))
+
+	output := "MISSPELLED WORDS\n\ndog\n\nWORDS MISSPELLED: 1\nWORDS IN DICTIONARY: 1\nWORDS IN TEXT: 2\n"
+	assert.NoError(t, verifySpellerOutput(workDir, "basic/dict", "basic/text", output))
+}
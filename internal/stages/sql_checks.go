@@ -0,0 +1,87 @@
+package stages
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// sqlReference 保存 staff 参考查询（sql_reference/<problem>/N.sql），编译进二进制，学生看不到
+//
+//go:embed sql_reference
+var sqlReference embed.FS
+
+// sqlCheck 是一道 SQL 题目：学生的 file 与参考查询在同一个数据库上运行，结果应一致
+type sqlCheck struct {
+	file string
+	// references 是可接受的参考查询文件名，任一匹配即通过；为空时使用与 file 同名的文件
+	references []string
+	// orderKeys 是参考查询末尾作为 ORDER BY 键的列数，键相同的行顺序任意
+	orderKeys int
+	spec      helpers.ResultSpec
+	// rules 是在 defaultSQLRules 之外、结果正确后还要满足的结构要求
+	rules []helpers.SQLRule
+}
+
+// defaultSQLRules 适用于所有 SQL 题目，只给出警告
+var defaultSQLRules = []helpers.SQLRule{
+	helpers.NoSelectStar(helpers.SQLWarning),
+	helpers.MaxQueryDuration(time.Second, helpers.SQLWarning),
+}
+
+// lookupRules 适用于需要先按名字查出 id 的题目：禁止硬编码 id，必须使用 JOIN 或子查询
+var lookupRules = []helpers.SQLRule{
+	helpers.NoHardcodedIDs(helpers.SQLFailure),
+	helpers.RequireJoinOrSubquery(helpers.SQLFailure),
+}
+
+// loadSQLReferences 读取一道题目的参考查询
+func loadSQLReferences(problem string, check sqlCheck) ([]string, error) {
+	names := check.references
+	if len(names) == 0 {
+		names = []string{check.file}
+	}
+	queries := make([]string, len(names))
+	for i, name := range names {
+		content, err := sqlReference.ReadFile(path.Join("sql_reference", problem, name))
+		if err != nil {
+			return nil, fmt.Errorf("missing reference query for %s: %v", check.file, err)
+		}
+		queries[i] = string(content)
+	}
+	return queries, nil
+}
+
+// runSQLChecks 依次运行各题目，与参考查询的结果比对
+func runSQLChecks(logger *logger.Logger, db *sql.DB, workDir, problem string, checks []sqlCheck) error {
+	for _, check := range checks {
+		logger.Infof("Testing %s produces correct result...", check.file)
+
+		references, err := loadSQLReferences(problem, check)
+		if err != nil {
+			return err
+		}
+		analysis, err := helpers.TestSQLReference(db, workDir, check.file, references, check.orderKeys, check.spec)
+		if err != nil {
+			return fmt.Errorf("%s: %v", check.file, err)
+		}
+
+		// 结果正确后检查查询结构（EXPLAIN QUERY PLAN 与词法分析）
+		failures, warnings := analysis.Check(append(append([]helpers.SQLRule(nil), defaultSQLRules...), check.rules...))
+		for _, w := range warnings {
+			logger.Errorf("Warning: %s %s", check.file, w)
+		}
+		if len(failures) > 0 {
+			return fmt.Errorf("%s produces the correct result, but %s", check.file, strings.Join(failures, "; "))
+		}
+
+		logger.Successf("✓ %s produces correct result", check.file)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package stages
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/logger"
+)
+
+// decoyNames 用于扰动数据库中新增的人物、歌曲和电影
+var decoyNames = []string{
+	"Avery Quill", "Rowan Vale", "Marlo Finch", "Juno Hale", "Emery Stroud",
+	"Tamsin Reyes", "Callum Pike", "Odessa Lark", "Bram Whitlock", "Nell Okafor",
+}
+
+// decoy 返回一个带随机编号的诱饵名字，几乎不可能与原数据重名
+func decoy(rng *rand.Rand) string {
+	return fmt.Sprintf("%s %d", decoyNames[rng.Intn(len(decoyNames))], rng.Intn(9000)+1000)
+}
+
+// songsPerturbations 重命名部分歌曲，微调部分能量值，
+// 并加入会出现在各题结果中的诱饵歌曲（Post Malone / Drake 的新歌、feat. 歌曲、高能量歌曲、超长歌曲）
+func songsPerturbations(rng *rand.Rand) []string {
+	m := rng.Intn(5) + 3
+	stmts := []string{
+		fmt.Sprintf("UPDATE songs SET name = name || ' - Remastered' WHERE id %% %d = %d", m, rng.Intn(m)),
+		fmt.Sprintf("UPDATE songs SET energy = MIN(1.0, energy + 0.05) WHERE id %% %d = %d", m, rng.Intn(m)),
+	}
+
+	insert := func(name, artist string, danceability, energy, valence, tempo float64, durationMs int) string {
+		return fmt.Sprintf(`INSERT INTO songs (id, name, artist_id, danceability, energy, key, loudness, speechiness, valence, tempo, duration_ms)
+			VALUES ((SELECT MAX(id) + 1 FROM songs), %s, (SELECT id FROM artists WHERE name = %s), %.3f, %.3f, 0, -5.0, 0.05, %.3f, %.3f, %d)`,
+			helpers.SQLQuote(name), helpers.SQLQuote(artist), danceability, energy, valence, tempo, durationMs)
+	}
+	stmts = append(stmts,
+		insert(decoy(rng), "Post Malone", 0.5, 0.1+rng.Float64()*0.2, 0.3, 90+rng.Float64()*60, 200000),
+		insert(decoy(rng), "Drake", 0.6, 0.9+rng.Float64()*0.1, 0.4, 90+rng.Float64()*60, 210000),
+		insert(decoy(rng)+" (feat. "+decoy(rng)+")", "Drake", 0.4, 0.5, 0.5, 90+rng.Float64()*60, 190000),
+		insert(decoy(rng), "Post Malone", 0.9, 0.95, 0.9, 40+rng.Float64()*200, 180000),
+		insert(decoy(rng), "Drake", 0.3, 0.3, 0.3, 40+rng.Float64()*200, 900000+rng.Intn(100000)),
+	)
+	return stmts
+}
+
+// moviesAnchors 是题目中按名字或标题查询的人物与电影，扰动时保持不变
+var moviesAnchors = []string{
+	"Emma Stone", "Kevin Bacon", "Chadwick Boseman", "Johnny Depp", "Helena Bonham Carter",
+	"Bradley Cooper", "Jennifer Lawrence", "Toy Story",
+}
+
+// moviesPerturbations 重命名部分人物与电影，平移出生年份和部分电影的年份，
+// 并加入会出现在各题结果中的诱饵电影与演员
+func moviesPerturbations(rng *rand.Rand) []string {
+	anchors := make([]string, len(moviesAnchors))
+	for i, a := range moviesAnchors {
+		anchors[i] = helpers.SQLQuote(a)
+	}
+	anchorList := strings.Join(anchors, ", ")
+
+	m := rng.Intn(5) + 3
+	shift := rng.Intn(3) + 1
+	if rng.Intn(2) == 0 {
+		shift = -shift
+	}
+	stmts := []string{
+		fmt.Sprintf("UPDATE people SET name = name || ' Jr.' WHERE id %% %d = %d AND name NOT IN (%s)", m, rng.Intn(m), anchorList),
+		fmt.Sprintf("UPDATE movies SET title = title || ' (Director''s Cut)' WHERE id %% %d = %d AND title NOT IN (%s) AND title NOT LIKE 'Harry Potter%%'",
+			m, rng.Intn(m), anchorList),
+		// Kevin Bacon 由出生年份 1958 确定，不平移
+		fmt.Sprintf("UPDATE people SET birth = birth + %d WHERE name != 'Kevin Bacon'", shift),
+		fmt.Sprintf("UPDATE movies SET year = year + 1 WHERE id %% %d = %d AND title NOT LIKE 'Harry Potter%%'", m, rng.Intn(m)),
+	}
+
+	movie := func(title string, year int, rating float64) []string {
+		return []string{
+			fmt.Sprintf("INSERT INTO movies (id, title, year) VALUES ((SELECT MAX(id) + 1 FROM movies), %s, %d)", helpers.SQLQuote(title), year),
+			fmt.Sprintf("INSERT INTO ratings (movie_id, rating, votes) VALUES ((SELECT MAX(id) FROM movies), %.1f, %d)", rating, rng.Intn(5000)+100),
+		}
+	}
+	person := func(name string, birth int) string {
+		return fmt.Sprintf("INSERT INTO people (id, name, birth) VALUES ((SELECT MAX(id) + 1 FROM people), %s, %d)", helpers.SQLQuote(name), birth)
+	}
+	// star 让人物（默认最新插入的人物）出演最新插入的电影
+	star := func(who string) string {
+		return fmt.Sprintf("INSERT INTO stars (movie_id, person_id) VALUES ((SELECT MAX(id) FROM movies), %s)", who)
+	}
+	newest := "(SELECT MAX(id) FROM people)"
+	named := func(name string) string {
+		return fmt.Sprintf("(SELECT id FROM people WHERE name = %s)", helpers.SQLQuote(name))
+	}
+
+	stmts = append(stmts, movie(decoy(rng), 2008, 5+float64(rng.Intn(40))/10)...)
+	stmts = append(stmts, movie(decoy(rng), 2019+rng.Intn(3), 10.0)...)
+	stmts = append(stmts, movie("Harry Potter and the "+decoy(rng), 2000+rng.Intn(20), 6.5)...)
+	stmts = append(stmts, movie(decoy(rng), 2012, 1+float64(rng.Intn(30))/10)...)
+	// 2010 年评分 9.0 以上的新电影及其导演
+	stmts = append(stmts, movie(decoy(rng), 2010, 9.0+float64(rng.Intn(10))/10)...)
+	stmts = append(stmts,
+		person(decoy(rng), 1940+rng.Intn(60)),
+		fmt.Sprintf("INSERT INTO directors (movie_id, person_id) VALUES ((SELECT MAX(id) FROM movies), %s)", newest),
+	)
+	// 2004 年的新电影，由一位新演员与 Chadwick Boseman、Kevin Bacon、两对搭档共同出演
+	stmts = append(stmts, movie(decoy(rng), 2004, 8.0+float64(rng.Intn(10))/10)...)
+	stmts = append(stmts,
+		person(decoy(rng), 1940+rng.Intn(60)),
+		star(newest),
+		star(named("Chadwick Boseman")),
+		star("(SELECT id FROM people WHERE name = 'Kevin Bacon' AND birth = 1958)"),
+		star(named("Johnny Depp")),
+		star(named("Helena Bonham Carter")),
+		star(named("Bradley Cooper")),
+		star(named("Jennifer Lawrence")),
+	)
+	// Toy Story 的新演员
+	stmts = append(stmts,
+		person(decoy(rng), 1940+rng.Intn(60)),
+		fmt.Sprintf("INSERT INTO stars (movie_id, person_id) VALUES ((SELECT id FROM movies WHERE title = 'Toy Story'), %s)", newest),
+	)
+	return stmts
+}
+
+// checkPerturbedSQL 在扰动后的数据库副本上重新运行学生查询与参考查询
+// 原数据库上通过、副本上不一致的查询很可能硬编码了答案（如 SELECT 1988;）
+func checkPerturbedSQL(logger *logger.Logger, workDir, problem, dbName string, checks []sqlCheck, perturb func(*rand.Rand) []string) error {
+	seed := helpers.SeedFromEnv("BOOTLLM_SQL_PERTURB_SEED")
+	logger.Infof("Testing queries on a perturbed copy of %s (seed %d)...", dbName, seed)
+
+	tmpDir, err := os.MkdirTemp("", "sql-perturb-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	copyPath := filepath.Join(tmpDir, dbName)
+	if err := helpers.PerturbDatabase(filepath.Join(workDir, dbName), copyPath, perturb(rand.New(rand.NewSource(seed)))); err != nil {
+		return err
+	}
+
+	db, err := helpers.OpenReadOnlyDB(copyPath)
+	if err != nil {
+		return fmt.Errorf("failed to open perturbed %s: %v", dbName, err)
+	}
+	defer db.Close()
+
+	var failures []string
+	for _, check := range checks {
+		references, err := loadSQLReferences(problem, check)
+		if err != nil {
+			return err
+		}
+		if _, err := helpers.TestSQLReference(db, workDir, check.file, references, check.orderKeys, check.spec); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", check.file, err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("%d queries match %s but not a perturbed copy with renamed people/titles, shifted years and extra rows; "+
+			"compute the answer from the data instead of hardcoding it (rerun with BOOTLLM_SQL_PERTURB_SEED=%d to reproduce)\n%s",
+			len(failures), dbName, seed, strings.Join(failures, "\n"))
+	}
+
+	logger.Successf("✓ queries also produce correct results on a perturbed %s", dbName)
+	return nil
+}
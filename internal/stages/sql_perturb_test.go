@@ -0,0 +1,95 @@
+package stages
+
+import (
+	"database/sql"
+	"math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// songsFixture 是 CS50 songs.db 的表结构与几行数据
+const songsFixture = `
+CREATE TABLE songs (id INTEGER, name TEXT, artist_id INTEGER, danceability REAL, energy REAL, key INTEGER, loudness REAL, speechiness REAL, valence REAL, tempo REAL, duration_ms INTEGER);
+CREATE TABLE artists (id INTEGER, name TEXT);
+INSERT INTO artists VALUES (1, 'Post Malone'), (2, 'Drake'), (3, 'Ed Sheeran');
+INSERT INTO songs VALUES
+	(1, 'Circles', 1, 0.695, 0.762, 0, -3.497, 0.0395, 0.553, 120.042, 215280),
+	(2, 'God''s Plan', 2, 0.754, 0.449, 7, -9.211, 0.109, 0.357, 77.169, 198973),
+	(3, 'Shape of You', 3, 0.825, 0.652, 1, -3.183, 0.0802, 0.931, 95.977, 233713),
+	(4, 'Sunflower', 1, 0.76, 0.479, 2, -5.574, 0.0466, 0.913, 89.911, 158040);
+`
+
+// moviesFixture 是 CS50 movies.db 的表结构，包含扰动时引用的人物与电影
+const moviesFixture = `
+CREATE TABLE movies (id INTEGER, title TEXT NOT NULL, year NUMERIC, PRIMARY KEY(id));
+CREATE TABLE people (id INTEGER, name TEXT NOT NULL, birth NUMERIC, PRIMARY KEY(id));
+CREATE TABLE stars (movie_id INTEGER NOT NULL, person_id INTEGER NOT NULL, FOREIGN KEY(movie_id) REFERENCES movies(id), FOREIGN KEY(person_id) REFERENCES people(id));
+CREATE TABLE directors (movie_id INTEGER NOT NULL, person_id INTEGER NOT NULL, FOREIGN KEY(movie_id) REFERENCES movies(id), FOREIGN KEY(person_id) REFERENCES people(id));
+CREATE TABLE ratings (movie_id INTEGER NOT NULL, rating REAL NOT NULL, votes INTEGER NOT NULL, FOREIGN KEY(movie_id) REFERENCES movies(id));
+INSERT INTO movies VALUES (114709, 'Toy Story', 1995), (241527, 'Harry Potter and the Sorcerer''s Stone', 2001), (1045658, 'Silver Linings Playbook', 2012);
+INSERT INTO people VALUES
+	(102, 'Kevin Bacon', 1958), (1569276, 'Chadwick Boseman', 1976), (136, 'Johnny Depp', 1963),
+	(307, 'Helena Bonham Carter', 1966), (177896, 'Bradley Cooper', 1975), (2225369, 'Jennifer Lawrence', 1990),
+	(1297015, 'Emma Stone', 1988), (158, 'Tom Hanks', 1956);
+INSERT INTO stars VALUES (114709, 158), (1045658, 177896), (1045658, 2225369);
+INSERT INTO ratings VALUES (114709, 8.3, 900000), (241527, 7.6, 700000), (1045658, 7.7, 650000);
+`
+
+// perturbFixture 在 fixture 数据库上应用一组扰动，返回扰动后的数据库
+func perturbFixture(t *testing.T, fixture string, stmts []string) *sql.DB {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "original.db")
+	db, err := sql.Open("sqlite3", src)
+	require.NoError(t, err)
+	_, err = db.Exec(fixture)
+	require.NoError(t, err)
+	db.Close()
+
+	dst := filepath.Join(dir, "perturbed.db")
+	require.NoError(t, helpers.PerturbDatabase(src, dst, stmts))
+	perturbed, err := sql.Open("sqlite3", dst)
+	require.NoError(t, err)
+	t.Cleanup(func() { perturbed.Close() })
+	return perturbed
+}
+
+func count(t *testing.T, db *sql.DB, query string) int64 {
+	rows, err := helpers.QueryRows(db, query)
+	require.NoError(t, err)
+	return rows[0][0].(int64)
+}
+
+func TestSongsPerturbations(t *testing.T) {
+	for seed := range int64(20) {
+		db := perturbFixture(t, songsFixture, songsPerturbations(rand.New(rand.NewSource(seed))))
+		assert.Equal(t, int64(9), count(t, db, "SELECT COUNT(*) FROM songs"), "seed %d", seed)
+		assert.Equal(t, int64(0), count(t, db, "SELECT COUNT(*) FROM songs WHERE artist_id IS NULL"), "seed %d", seed)
+		assert.Equal(t, int64(9), count(t, db, "SELECT COUNT(DISTINCT id) FROM songs"), "seed %d", seed)
+		assert.Equal(t, int64(0), count(t, db, "SELECT COUNT(*) FROM songs WHERE energy > 1.0"), "seed %d", seed)
+	}
+}
+
+func TestMoviesPerturbations(t *testing.T) {
+	for seed := range int64(20) {
+		db := perturbFixture(t, moviesFixture, moviesPerturbations(rand.New(rand.NewSource(seed))))
+		violations, err := helpers.ForeignKeyViolations(db)
+		require.NoError(t, err)
+		assert.Empty(t, violations, "seed %d", seed)
+
+		assert.Equal(t, int64(9), count(t, db, "SELECT COUNT(*) FROM movies"), "seed %d", seed)
+		assert.Equal(t, int64(9), count(t, db, "SELECT COUNT(*) FROM ratings"), "seed %d", seed)
+		assert.Equal(t, int64(1958), count(t, db, "SELECT birth FROM people WHERE name = 'Kevin Bacon'"), "seed %d", seed)
+		for _, anchor := range moviesAnchors[:len(moviesAnchors)-1] {
+			assert.Equal(t, int64(1), count(t, db, "SELECT COUNT(*) FROM people WHERE name = "+helpers.SQLQuote(anchor)), "seed %d: %s", seed, anchor)
+		}
+		assert.Equal(t, int64(1), count(t, db, "SELECT COUNT(*) FROM movies WHERE title = 'Toy Story'"), "seed %d", seed)
+		// 2004 年的诱饵电影由新演员与六位锚点人物出演，Toy Story 多了一位演员
+		assert.Equal(t, int64(7), count(t, db, "SELECT COUNT(*) FROM stars JOIN movies ON movies.id = stars.movie_id WHERE year = 2004"), "seed %d", seed)
+		assert.Equal(t, int64(2), count(t, db, "SELECT COUNT(*) FROM stars WHERE movie_id = 114709"), "seed %d", seed)
+		assert.Equal(t, int64(1), count(t, db, "SELECT COUNT(*) FROM directors"), "seed %d", seed)
+	}
+}
@@ -0,0 +1 @@
+SELECT birth FROM people WHERE name = 'Emma Stone';
@@ -0,0 +1 @@
+SELECT title FROM movies WHERE year >= 2018 ORDER BY title;
@@ -0,0 +1 @@
+SELECT COUNT(*) FROM ratings WHERE rating = 10.0;
@@ -0,0 +1 @@
+SELECT title, year FROM movies WHERE title LIKE 'Harry Potter%' ORDER BY year;
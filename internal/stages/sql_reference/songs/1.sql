@@ -0,0 +1 @@
+SELECT name FROM songs;
@@ -0,0 +1 @@
+SELECT name, tempo FROM songs ORDER BY tempo;
@@ -0,0 +1 @@
+SELECT name FROM songs ORDER BY duration_ms DESC LIMIT 5;
@@ -0,0 +1 @@
+SELECT AVG(energy) FROM songs;
@@ -50,6 +50,7 @@ func GetDefinition() tester_definition.TesterDefinition {
 			songsTestCase(),
 			moviesTestCase(),
 			fiftyvilleTestCase(),
+			prophecyTestCase(),
 
 			// Week 9: Flask
 			financeTestCase(),
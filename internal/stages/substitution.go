@@ -2,6 +2,7 @@ package stages
 
 import (
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/bootllm/llm100x-tester/internal/helpers"
@@ -13,7 +14,7 @@ import (
 func substitutionTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "substitution",
-		Timeout:  30 * time.Second,
+		Timeout:  helpers.FuzzTimeout(30 * time.Second),
 		TestFunc: testSubstitution,
 	}
 }
@@ -168,6 +169,30 @@ func testSubstitution(harness *test_case_harness.TestCaseHarness) error {
 		logger.Successf("✓ %s", tc.name)
 	}
 
+	// 5. 可选：模糊测试 (BOOTLLM_FUZZ=1，默认关闭)
+	if helpers.FuzzEnabled() {
+		if err := runFuzzPass(harness, "substitution.c", substitutionFuzzInputs()); err != nil {
+			return err
+		}
+	}
+
 	logger.Successf("All substitution tests passed!")
 	return nil
 }
+
+// substitutionFuzzInputs 生成 substitution 的模糊测试输入：不友好的 key 以及不友好的明文
+func substitutionFuzzInputs() []helpers.FuzzInput {
+	var inputs []helpers.FuzzInput
+	keys := []string{
+		"",
+		strings.Repeat("A", 100000),
+		"ZYXWVUTSRQPONMLKJIHGFEDCB\xff",
+		"ZYXWVUTSRQPONMLKJIHGFEDCBÄ",
+		"ZYXWVUTSRQPONMLKJIHGFEDCB",
+		"ZYXWVUTSRQPONMLKJIHGFEDCBAA",
+	}
+	for _, key := range keys {
+		inputs = append(inputs, helpers.FuzzInput{Args: []string{key}, Stdin: "hello, world\n"})
+	}
+	return append(inputs, textFuzzInputs("NJQSUYBRXMOPFTHZVAWCGILKED")...)
+}
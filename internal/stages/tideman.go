@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
 	"github.com/bootllm/tester-utils/runner"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
@@ -17,7 +18,7 @@ import (
 func tidemanTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "tideman",
-		Timeout:  60 * time.Second,
+		Timeout:  helpers.ComplexityTimeout(60 * time.Second),
 		TestFunc: testTideman,
 	}
 }
@@ -38,7 +39,7 @@ func testTideman(harness *test_case_harness.TestCaseHarness) error {
 	cmd := exec.Command("clang", "-o", "tideman", "tideman.c", "-I..", "-lm", "-Wall", "-Werror")
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("tideman.c does not compile: %s\n%s", err, string(out))
+		return fmt.Errorf("tideman.c does not compile: %s\n%s", err, helpers.ExplainClangOutput(workDir, string(out)))
 	}
 	logger.Successf("tideman compiles")
 
@@ -67,7 +68,7 @@ func testTideman(harness *test_case_harness.TestCaseHarness) error {
 
 	// 编译测试程序
 	logger.Infof("Compiling test harness...")
-	cmd = exec.Command("clang", "-o", "tideman_test", "tideman_combined_test.c", "-I..", "-lm", "-Wall")
+	cmd = exec.Command("clang", "-g", "-o", "tideman_test", "tideman_combined_test.c", "-I..", "-lm", "-Wall")
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("test harness does not compile: %s\n%s", err, string(out))
@@ -97,7 +98,7 @@ func testTideman(harness *test_case_harness.TestCaseHarness) error {
 			Exit(0)
 
 		if err := r.Error(); err != nil {
-			return fmt.Errorf("test failed for %s: %v", tc.name, err)
+			return fmt.Errorf("test failed for %s: %v", tc.name, helpers.ExplainCrash(r, err, workDir, "tideman_test", tc.setup, tc.test))
 		}
 
 		logger.Successf("✓ %s", tc.name)
@@ -124,7 +125,7 @@ func testTideman(harness *test_case_harness.TestCaseHarness) error {
 			Exit(0)
 
 		if err := r.Error(); err != nil {
-			return fmt.Errorf("test failed for %s: %v", tc.name, err)
+			return fmt.Errorf("test failed for %s: %v", tc.name, helpers.ExplainCrash(r, err, workDir, "tideman_test", tc.setup, tc.test))
 		}
 
 		logger.Successf("✓ %s", tc.name)
@@ -153,7 +154,7 @@ func testTideman(harness *test_case_harness.TestCaseHarness) error {
 			Exit(0)
 
 		if err := r.Error(); err != nil {
-			return fmt.Errorf("test failed for %s: %v", tc.name, err)
+			return fmt.Errorf("test failed for %s: %v", tc.name, helpers.ExplainCrash(r, err, workDir, "tideman_test", tc.setup, tc.test))
 		}
 
 		logger.Successf("✓ %s", tc.name)
@@ -167,7 +168,7 @@ func testTideman(harness *test_case_harness.TestCaseHarness) error {
 		Stdout("0 2 0 1 2 1 ").
 		Exit(0)
 	if err := r.Error(); err != nil {
-		return fmt.Errorf("test failed for sort_pairs: %v", err)
+		return fmt.Errorf("test failed for sort_pairs: %v", helpers.ExplainCrash(r, err, workDir, "tideman_test", "3", "8"))
 	}
 	logger.Successf("✓ sort_pairs sorts pairs of candidates by margin of victory")
 
@@ -193,7 +194,7 @@ func testTideman(harness *test_case_harness.TestCaseHarness) error {
 			Exit(0)
 
 		if err := r.Error(); err != nil {
-			return fmt.Errorf("test failed for %s: %v", tc.name, err)
+			return fmt.Errorf("test failed for %s: %v", tc.name, helpers.ExplainCrash(r, err, workDir, "tideman_test", tc.setup, tc.test))
 		}
 
 		logger.Successf("✓ %s", tc.name)
@@ -219,7 +220,7 @@ func testTideman(harness *test_case_harness.TestCaseHarness) error {
 			Exit(0)
 
 		if err := r.Error(); err != nil {
-			return fmt.Errorf("test failed for %s: %v", tc.name, err)
+			return fmt.Errorf("test failed for %s: %v", tc.name, helpers.ExplainCrash(r, err, workDir, "tideman_test", tc.setup, tc.test))
 		}
 
 		stdout := strings.TrimSpace(r.GetStdout())
@@ -230,6 +231,25 @@ func testTideman(harness *test_case_harness.TestCaseHarness) error {
 		logger.Successf("✓ %s", tc.name)
 	}
 
+	// 可选：复杂度检查 (BOOTLLM_COMPLEXITY=1，默认关闭)
+	if helpers.ComplexityEnabled() {
+		if err := checkTidemanScaling(logger, workDir); err != nil {
+			return err
+		}
+	}
+
+	// 端到端：以命令行参数和交互提示运行完整的 ./tideman
+	if err := checkElectionMain(logger, workDir, "tideman", electionMainCases("tideman", 3)); err != nil {
+		return err
+	}
+
+	// 随机选举：运行完整的 ./tideman，与 Go 参考计票比对胜者
+	logger.Infof("Testing tideman on random elections...")
+	if err := checkRandomElections(workDir, tidemanElections); err != nil {
+		return err
+	}
+	logger.Successf("✓ tideman elects the correct winner in random elections")
+
 	// 清理测试文件
 	os.Remove(testFilePath)
 	os.Remove(filepath.Join(workDir, "tideman_test"))
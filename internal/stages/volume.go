@@ -4,31 +4,19 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"math"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"time"
 
+	"github.com/bootllm/llm100x-tester/internal/helpers"
+	"github.com/bootllm/tester-utils/random"
 	"github.com/bootllm/tester-utils/test_case_harness"
 	"github.com/bootllm/tester-utils/tester_definition"
 )
 
-// 期望的哈希值 (CS50 提供的)
-// permits either truncating or rounding the floats to ints
-var hashesHalf = []string{
-	"268f2deee976fcc8dcc915be63cd3d4ac003a73a4b8bfd6f7b95c441a42ed1ec",
-	"9a92829dcd2de343235607de1db01d374a44bd58738cb4070e354dead02e50c1",
-}
-
-var hashesTenth = []string{
-	"4481b5a438d359718000dfd58e2a32a7b109eb4a5590e0650c6bd295979c64fc",
-	"dae24291174811b2df95f6836e023e855c77bdc5bee3294542ba4bf1b95de2cc",
-}
-
-var hashesDouble = []string{
-	"3d83603745302935c067379b704573e5addb4356ad407041f0a698070e6e4e7b",
-}
-
 func volumeTestCase() tester_definition.TestCase {
 	return tester_definition.TestCase{
 		Slug:     "volume",
@@ -53,7 +41,7 @@ func testVolume(harness *test_case_harness.TestCaseHarness) error {
 	cmd := exec.Command("clang", "-o", "volume", "volume.c", "-I..", "-lm", "-Wall", "-Werror")
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("volume.c does not compile: %s\n%s", err, string(out))
+		return fmt.Errorf("volume.c does not compile: %s\n%s", err, helpers.ExplainClangOutput(workDir, string(out)))
 	}
 	logger.Successf("volume compiles")
 
@@ -62,67 +50,100 @@ func testVolume(harness *test_case_harness.TestCaseHarness) error {
 		return fmt.Errorf("input.wav does not exist")
 	}
 
-	// 4. 测试 factor 0.5
-	logger.Infof("Testing reduces audio volume, factor of 0.5 correctly...")
+	// 4. 测试 CS50 提供的 input.wav
 	outputPath := filepath.Join(workDir, "output.wav")
-	cmd = exec.Command("./volume", "input.wav", "output.wav", "0.5")
-	cmd.Dir = workDir
-	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("volume failed with factor 0.5: %s\n%s", err, string(out))
+	volumeTests := []struct {
+		factor string
+		name   string
+	}{
+		{"0.5", "reduces audio volume, factor of 0.5 correctly"},
+		{"0.1", "reduces audio volume, factor of 0.1 correctly"},
+		{"2", "increases audio volume, factor of 2 correctly"},
+	}
+	for _, tc := range volumeTests {
+		logger.Infof("Testing %s...", tc.name)
+		if err := runVolume(workDir, "input.wav", "output.wav", tc.factor); err != nil {
+			return fmt.Errorf("audio is not correctly altered, factor of %s: %v", tc.factor, err)
+		}
+		logger.Successf("✓ %s", tc.name)
 	}
 
-	hash, err := hashFile(outputPath)
+	// 5. 随机系数与合成的 WAV
+	logger.Infof("Testing random factors on synthetic audio...")
+	tmpDir, err := os.MkdirTemp("", "volume-")
 	if err != nil {
-		return fmt.Errorf("could not hash output.wav: %v", err)
+		return err
 	}
-
-	if !contains(hashesHalf, hash) {
-		return fmt.Errorf("audio is not correctly altered, factor of 0.5 (hash: %s)", hash)
+	defer os.RemoveAll(tmpDir)
+	for i := 0; i < 3; i++ {
+		factor := fmt.Sprintf("%.2f", float64(random.RandomInt(1, 300))/100)
+		input := filepath.Join(tmpDir, fmt.Sprintf("input-%d.wav", i))
+		if err := os.WriteFile(input, helpers.EncodeWAV(syntheticSamples(random.RandomInt(1000, 20000), factor), 44100), 0644); err != nil {
+			return err
+		}
+		if err := runVolume(workDir, input, filepath.Join(tmpDir, "output.wav"), factor); err != nil {
+			return fmt.Errorf("audio is not correctly altered on synthetic input, factor of %s: %v", factor, err)
+		}
 	}
-	logger.Successf("✓ reduces audio volume, factor of 0.5 correctly")
+	logger.Successf("✓ scales synthetic audio by random factors correctly")
+
+	// 清理编译产物
+	os.Remove(outputPath)
+	os.Remove(filepath.Join(workDir, "volume"))
+
+	logger.Successf("All volume tests passed!")
+	return nil
+}
 
-	// 5. 测试 factor 0.1
-	logger.Infof("Testing reduces audio volume, factor of 0.1 correctly...")
-	cmd = exec.Command("./volume", "input.wav", "output.wav", "0.1")
+// runVolume 运行 ./volume input output factor，并逐样本检查输出
+func runVolume(workDir, input, output, factor string) error {
+	cmd := exec.Command("./volume", input, output, factor)
 	cmd.Dir = workDir
 	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("volume failed with factor 0.1: %s\n%s", err, string(out))
+		return fmt.Errorf("volume failed: %v\n%s", helpers.ExplainCmdCrash(cmd, err), string(out))
 	}
 
-	hash, err = hashFile(outputPath)
+	in, err := readWAV(workDir, input)
 	if err != nil {
-		return fmt.Errorf("could not hash output.wav: %v", err)
+		return fmt.Errorf("could not read %s: %v", input, err)
 	}
-
-	if !contains(hashesTenth, hash) {
-		return fmt.Errorf("audio is not correctly altered, factor of 0.1 (hash: %s)", hash)
+	out, err := readWAV(workDir, output)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %v", output, err)
 	}
-	logger.Successf("✓ reduces audio volume, factor of 0.1 correctly")
-
-	// 6. 测试 factor 2
-	logger.Infof("Testing increases audio volume, factor of 2 correctly...")
-	cmd = exec.Command("./volume", "input.wav", "output.wav", "2")
-	cmd.Dir = workDir
-	if out, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("volume failed with factor 2: %s\n%s", err, string(out))
+	f, err := strconv.ParseFloat(factor, 64)
+	if err != nil {
+		return err
 	}
+	return helpers.CheckScaledWAV(in, out, f)
+}
 
-	hash, err = hashFile(outputPath)
+// readWAV 读取并解析 WAV 文件，相对路径相对于 workDir
+func readWAV(workDir, path string) (*helpers.WAV, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(workDir, path)
+	}
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("could not hash output.wav: %v", err)
+		return nil, err
 	}
+	return helpers.ParseWAV(data)
+}
 
-	if !contains(hashesDouble, hash) {
-		return fmt.Errorf("audio is not correctly altered, factor of 2 (hash: %s)", hash)
+// syntheticSamples 生成 n 个样本的合成音频：两个正弦波叠加随机噪声
+// 振幅按 factor 限制，保证放大后不会溢出 int16
+func syntheticSamples(n int, factor string) []int16 {
+	f, _ := strconv.ParseFloat(factor, 64)
+	amplitude := math.Min(32767, 32767/math.Max(f, 1)) * 0.9
+	freq1 := float64(random.RandomInt(100, 1000))
+	freq2 := float64(random.RandomInt(1000, 5000))
+	samples := make([]int16, n)
+	for i := range samples {
+		t := float64(i) / 44100
+		v := 0.6*math.Sin(2*math.Pi*freq1*t) + 0.3*math.Sin(2*math.Pi*freq2*t) + 0.1*(2*float64(random.RandomInt(0, 1000))/1000-1)
+		samples[i] = int16(v * amplitude)
 	}
-	logger.Successf("✓ increases audio volume, factor of 2 correctly")
-
-	// 清理编译产物
-	os.Remove(outputPath)
-	os.Remove(filepath.Join(workDir, "volume"))
-
-	logger.Successf("All volume tests passed!")
-	return nil
+	return samples
 }
 
 // hashFile 计算文件的 SHA256 哈希
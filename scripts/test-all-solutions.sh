@@ -43,6 +43,7 @@ STAGES=(
     "songs"
     "movies"
     "fiftyville"
+    "prophecy"
     "finance"
 )
 